@@ -0,0 +1,281 @@
+package xutils
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+)
+
+var (
+	ErrBloomIncompatible = errors.New("bloom filters have different size or hash count")
+	ErrBloomInvalidData  = errors.New("invalid bloom filter data")
+)
+
+const (
+	bloomTypeBasic    = 1
+	bloomTypeCounting = 2
+	bloomHeaderSize   = 1 + 4 + 8
+)
+
+// BloomFilter 布隆过滤器，用于判断元素是否 "可能存在" 或 "一定不存在"
+// 使用 MurmurHash3 128位哈希的两个64位结果做双重哈希得到 k 个位置，不是并发安全的
+type BloomFilter struct {
+	m    uint64
+	k    uint32
+	bits []uint64
+}
+
+// NewBloomFilter 根据预计的元素个数 n 和期望的误判率 p 创建布隆过滤器
+func NewBloomFilter(n uint64, p float64) *BloomFilter {
+	m, k := bloomEstimate(n, p)
+	return NewBloomFilterWithSize(m, k)
+}
+
+// NewBloomFilterWithSize 使用指定的位数 m 和哈希函数个数 k 创建布隆过滤器
+func NewBloomFilterWithSize(m uint64, k uint32) *BloomFilter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &BloomFilter{m: m, k: k, bits: make([]uint64, (m+63)/64)}
+}
+
+// bloomEstimate 计算最优的位数 m = -n*ln(p)/(ln2)^2 和哈希函数个数 k = m/n*ln2
+func bloomEstimate(n uint64, p float64) (uint64, uint32) {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return uint64(m), uint32(k)
+}
+
+// bloomLocations 使用双重哈希 h1 + i*h2 计算 k 个位置
+func bloomLocations(data []byte, k uint32, m uint64, fn func(loc uint64) bool) {
+	h1, h2 := Murmur3Hash128(data, 0)
+	for i := uint32(0); i < k; i++ {
+		if !fn((h1 + uint64(i)*h2) % m) {
+			return
+		}
+	}
+}
+
+// Cap 返回位数 m
+func (f *BloomFilter) Cap() uint64 {
+	return f.m
+}
+
+// K 返回哈希函数个数
+func (f *BloomFilter) K() uint32 {
+	return f.k
+}
+
+// Add 添加元素
+func (f *BloomFilter) Add(data []byte) {
+	bloomLocations(data, f.k, f.m, func(loc uint64) bool {
+		f.bits[loc/64] |= 1 << (loc % 64)
+		return true
+	})
+}
+
+// AddString 添加字符串元素
+func (f *BloomFilter) AddString(s string) {
+	f.Add([]byte(s))
+}
+
+// Test 判断元素是否可能存在，返回 false 时一定不存在
+func (f *BloomFilter) Test(data []byte) bool {
+	found := true
+	bloomLocations(data, f.k, f.m, func(loc uint64) bool {
+		found = f.bits[loc/64]&(1<<(loc%64)) != 0
+		return found
+	})
+	return found
+}
+
+// TestString 判断字符串元素是否可能存在
+func (f *BloomFilter) TestString(s string) bool {
+	return f.Test([]byte(s))
+}
+
+// Count 根据已置位的比特数估算已添加的元素个数
+func (f *BloomFilter) Count() uint64 {
+	var x int
+	for _, w := range f.bits {
+		x += bits.OnesCount64(w)
+	}
+	if uint64(x) >= f.m {
+		return math.MaxUint64
+	}
+	n := -float64(f.m) / float64(f.k) * math.Log(1-float64(x)/float64(f.m))
+	return uint64(math.Round(n))
+}
+
+// Clear 清空所有元素
+func (f *BloomFilter) Clear() {
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
+
+// Union 合并另一个布隆过滤器的元素，两者的 m 和 k 必须相同
+func (f *BloomFilter) Union(other *BloomFilter) error {
+	if f.m != other.m || f.k != other.k {
+		return ErrBloomIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] |= other.bits[i]
+	}
+	return nil
+}
+
+// Intersect 只保留两个布隆过滤器都可能存在的元素，两者的 m 和 k 必须相同
+func (f *BloomFilter) Intersect(other *BloomFilter) error {
+	if f.m != other.m || f.k != other.k {
+		return ErrBloomIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] &= other.bits[i]
+	}
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+// 格式为 type(1) + k(4) + m(8) + 位图，均为大端序
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	b := bloomHeader(bloomTypeBasic, f.k, f.m, len(f.bits)*8)
+	for _, w := range f.bits {
+		b = appendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	k, m, payload, err := parseBloomHeader(data, bloomTypeBasic)
+	if err != nil {
+		return err
+	}
+	if len(payload)%8 != 0 || m > uint64(len(payload))*8 || m+64 <= uint64(len(payload))*8 {
+		return ErrBloomInvalidData
+	}
+	words := make([]uint64, len(payload)/8)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(payload[i*8:])
+	}
+	f.m, f.k, f.bits = m, k, words
+	return nil
+}
+
+func bloomHeader(typ byte, k uint32, m uint64, size int) []byte {
+	b := make([]byte, 1, bloomHeaderSize+size)
+	b[0] = typ
+	b = appendUint32(b, k)
+	return appendUint64(b, m)
+}
+
+func parseBloomHeader(data []byte, typ byte) (uint32, uint64, []byte, error) {
+	if len(data) < bloomHeaderSize || data[0] != typ {
+		return 0, 0, nil, ErrBloomInvalidData
+	}
+	k := binary.BigEndian.Uint32(data[1:])
+	m := binary.BigEndian.Uint64(data[5:])
+	if k == 0 || m == 0 {
+		return 0, 0, nil, ErrBloomInvalidData
+	}
+	return k, m, data[bloomHeaderSize:], nil
+}
+
+// CountingBloomFilter 计数布隆过滤器，每个位置使用8位计数器，支持删除元素
+// 计数器达到255后不再变化，也不会因删除而减少，不是并发安全的
+type CountingBloomFilter struct {
+	m        uint64
+	k        uint32
+	counters []uint8
+}
+
+// NewCountingBloomFilter 根据预计的元素个数 n 和期望的误判率 p 创建计数布隆过滤器
+func NewCountingBloomFilter(n uint64, p float64) *CountingBloomFilter {
+	m, k := bloomEstimate(n, p)
+	return &CountingBloomFilter{m: m, k: k, counters: make([]uint8, m)}
+}
+
+// Add 添加元素
+func (f *CountingBloomFilter) Add(data []byte) {
+	bloomLocations(data, f.k, f.m, func(loc uint64) bool {
+		if f.counters[loc] < math.MaxUint8 {
+			f.counters[loc]++
+		}
+		return true
+	})
+}
+
+// AddString 添加字符串元素
+func (f *CountingBloomFilter) AddString(s string) {
+	f.Add([]byte(s))
+}
+
+// Test 判断元素是否可能存在，返回 false 时一定不存在
+func (f *CountingBloomFilter) Test(data []byte) bool {
+	found := true
+	bloomLocations(data, f.k, f.m, func(loc uint64) bool {
+		found = f.counters[loc] > 0
+		return found
+	})
+	return found
+}
+
+// TestString 判断字符串元素是否可能存在
+func (f *CountingBloomFilter) TestString(s string) bool {
+	return f.Test([]byte(s))
+}
+
+// Remove 删除元素，元素一定不存在时返回 false 且不做任何修改
+// 只能删除已添加过的元素，否则可能导致其他元素被误判为不存在
+func (f *CountingBloomFilter) Remove(data []byte) bool {
+	if !f.Test(data) {
+		return false
+	}
+	bloomLocations(data, f.k, f.m, func(loc uint64) bool {
+		// 已饱和的计数器无法确定真实次数，不再减少；多个位置相同时计数器可能已减为0，不能再减
+		if c := f.counters[loc]; c > 0 && c < math.MaxUint8 {
+			f.counters[loc]--
+		}
+		return true
+	})
+	return true
+}
+
+// RemoveString 删除字符串元素
+func (f *CountingBloomFilter) RemoveString(s string) bool {
+	return f.Remove([]byte(s))
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+func (f *CountingBloomFilter) MarshalBinary() ([]byte, error) {
+	b := bloomHeader(bloomTypeCounting, f.k, f.m, len(f.counters))
+	return append(b, f.counters...), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+func (f *CountingBloomFilter) UnmarshalBinary(data []byte) error {
+	k, m, payload, err := parseBloomHeader(data, bloomTypeCounting)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) != m {
+		return ErrBloomInvalidData
+	}
+	f.m, f.k = m, k
+	f.counters = append([]uint8(nil), payload...)
+	return nil
+}
@@ -0,0 +1,134 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n = 10000
+	f := NewBloomFilter(n, 0.01)
+	assert.Equal(t, uint64(95851), f.Cap())
+	assert.Equal(t, uint32(7), f.K())
+
+	for i := 0; i < n; i++ {
+		f.AddString("item:" + strconv.Itoa(i))
+	}
+	for i := 0; i < n; i++ {
+		assert.True(t, f.TestString("item:"+strconv.Itoa(i)))
+	}
+	// 误判率接近期望值
+	fp := 0
+	for i := 0; i < n; i++ {
+		if f.TestString("other:" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	assert.InDelta(t, 0.01, float64(fp)/n, 0.005)
+	assert.InDelta(t, n, f.Count(), n*0.05)
+
+	f.Clear()
+	assert.False(t, f.TestString("item:1"))
+	assert.Equal(t, uint64(0), f.Count())
+}
+
+func TestBloomFilterUnion(t *testing.T) {
+	a := NewBloomFilter(1000, 0.001)
+	b := NewBloomFilter(1000, 0.001)
+	a.AddString("a")
+	a.AddString("both")
+	b.AddString("b")
+	b.AddString("both")
+
+	u := NewBloomFilter(1000, 0.001)
+	assert.Nil(t, u.Union(a))
+	assert.Nil(t, u.Union(b))
+	assert.True(t, u.TestString("a"))
+	assert.True(t, u.TestString("b"))
+	assert.True(t, u.TestString("both"))
+
+	assert.Nil(t, a.Intersect(b))
+	assert.True(t, a.TestString("both"))
+	assert.False(t, a.TestString("a"))
+	assert.False(t, a.TestString("b"))
+
+	assert.Equal(t, ErrBloomIncompatible, a.Union(NewBloomFilter(100, 0.001)))
+	assert.Equal(t, ErrBloomIncompatible, a.Intersect(NewBloomFilterWithSize(a.Cap(), a.K()+1)))
+}
+
+func TestBloomFilterMarshal(t *testing.T) {
+	f := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	data, err := f.MarshalBinary()
+	assert.Nil(t, err)
+
+	var g BloomFilter
+	assert.Nil(t, g.UnmarshalBinary(data))
+	assert.Equal(t, f.Cap(), g.Cap())
+	assert.Equal(t, f.K(), g.K())
+	for i := 0; i < 1000; i++ {
+		assert.True(t, g.Test([]byte(strconv.Itoa(i))))
+	}
+
+	assert.Equal(t, ErrBloomInvalidData, g.UnmarshalBinary(data[:len(data)-1]))
+	assert.Equal(t, ErrBloomInvalidData, g.UnmarshalBinary(data[:5]))
+	assert.Equal(t, ErrBloomInvalidData, g.UnmarshalBinary(append(data, make([]byte, 8)...)))
+	c := NewCountingBloomFilter(10, 0.01)
+	cdata, _ := c.MarshalBinary()
+	assert.Equal(t, ErrBloomInvalidData, g.UnmarshalBinary(cdata))
+}
+
+func TestCountingBloomFilter(t *testing.T) {
+	f := NewCountingBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.AddString(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.TestString(strconv.Itoa(i)))
+	}
+
+	// 删除后不再存在，其他元素不受影响
+	for i := 0; i < 500; i++ {
+		assert.True(t, f.RemoveString(strconv.Itoa(i)))
+	}
+	removed := 0
+	for i := 0; i < 500; i++ {
+		if !f.TestString(strconv.Itoa(i)) {
+			removed++
+		}
+	}
+	assert.True(t, removed > 480)
+	for i := 500; i < 1000; i++ {
+		assert.True(t, f.TestString(strconv.Itoa(i)))
+	}
+	assert.False(t, f.RemoveString("not exists"))
+
+	// 重复添加需要删除同样次数
+	f.AddString("dup")
+	f.AddString("dup")
+	f.RemoveString("dup")
+	assert.True(t, f.TestString("dup"))
+	f.RemoveString("dup")
+	assert.False(t, f.TestString("dup"))
+
+	data, err := f.MarshalBinary()
+	assert.Nil(t, err)
+	var g CountingBloomFilter
+	assert.Nil(t, g.UnmarshalBinary(data))
+	assert.Equal(t, *f, g)
+	assert.Equal(t, ErrBloomInvalidData, g.UnmarshalBinary(data[:len(data)-1]))
+
+	// 计数器为0时不会回绕为255
+	h := &CountingBloomFilter{m: 1, k: 3, counters: []uint8{1}}
+	assert.True(t, h.RemoveString("a"))
+	assert.Equal(t, []uint8{0}, h.counters)
+	assert.False(t, h.TestString("a"))
+
+	h = &CountingBloomFilter{m: 1, k: 1, counters: []uint8{math.MaxUint8}}
+	assert.True(t, h.RemoveString("a"))
+	assert.Equal(t, []uint8{math.MaxUint8}, h.counters)
+}
@@ -0,0 +1,286 @@
+package xutils
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ChecksumFormat 校验文件的格式
+type ChecksumFormat uint8
+
+const (
+	ChecksumGNU ChecksumFormat = iota // sha256sum 等 GNU coreutils 的默认格式，如 "<hex>  file"
+	ChecksumBSD                       // BSD 格式（sha256sum --tag），如 "SHA256 (file) = <hex>"
+)
+
+// ChecksumStatus 校验结果
+type ChecksumStatus uint8
+
+const (
+	ChecksumOK ChecksumStatus = iota
+	ChecksumFailed
+	ChecksumMissing
+)
+
+func (s ChecksumStatus) String() string {
+	switch s {
+	case ChecksumOK:
+		return "OK"
+	case ChecksumFailed:
+		return "FAILED"
+	case ChecksumMissing:
+		return "MISSING"
+	}
+	return "ChecksumStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ChecksumEntry 校验文件中的一行
+type ChecksumEntry struct {
+	Algo   HashAlgo
+	Path   string
+	Sum    []byte
+	Binary bool // GNU 格式中文件名前的 "*" 标记，只影响输出格式
+}
+
+// ChecksumResult 单个文件的校验结果，Err 为读取文件时的错误
+type ChecksumResult struct {
+	Path   string
+	Status ChecksumStatus
+	Err    error
+}
+
+// 不同十六进制长度对应的默认算法，与 coreutils 保持一致
+var checksumHexLens = map[int]HashAlgo{
+	32:  MD5,
+	40:  SHA1,
+	56:  SHA224,
+	64:  SHA256,
+	96:  SHA384,
+	128: SHA512,
+}
+
+// 与 coreutils 输出一致的 BSD 格式算法标记
+var checksumBSDTags = map[HashAlgo]string{
+	BLAKE2B_512: "BLAKE2b",
+}
+
+// ParseChecksums 解析校验文件的内容，支持 GNU 格式（含 "*" 二进制标记和 "\" 转义的文件名）和 BSD 格式
+// GNU 格式的行没有算法信息，使用 algo；algo 为 0 时根据哈希值的长度推断
+// 空行和 "#" 开头的注释行会被忽略
+func ParseChecksums(rd io.Reader, algo HashAlgo) ([]ChecksumEntry, error) {
+	entries := make([]ChecksumEntry, 0)
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 4096), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		entry, err := parseChecksumLine(line, algo)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func parseChecksumLine(line string, algo HashAlgo) (ChecksumEntry, error) {
+	var entry ChecksumEntry
+	escaped := line[0] == '\\'
+	if escaped {
+		line = line[1:]
+	}
+
+	var sum string
+	if i := strings.IndexByte(line, ' '); i > 0 && isHexString(line[:i]) && i+1 < len(line) && (line[i+1] == ' ' || line[i+1] == '*') {
+		// GNU 格式：hex  file 或 hex *file
+		sum = line[:i]
+		entry.Binary = line[i+1] == '*'
+		entry.Path = line[i+2:]
+		entry.Algo = algo
+		if entry.Algo == 0 {
+			entry.Algo = checksumHexLens[len(sum)]
+		}
+	} else {
+		// BSD 格式：ALGO (file) = hex
+		i = strings.Index(line, " (")
+		j := strings.LastIndex(line, ") = ")
+		if i <= 0 || j < i {
+			return entry, errors.New("invalid checksum line")
+		}
+		a, err := ParseHashAlgo(line[:i])
+		if err != nil {
+			return entry, err
+		}
+		entry.Algo = a
+		entry.Path = line[i+2 : j]
+		sum = line[j+4:]
+	}
+	if entry.Path == "" {
+		return entry, errors.New("invalid checksum line")
+	}
+	if escaped {
+		entry.Path = unescapeChecksumPath(entry.Path)
+	}
+
+	h, err := newHash(entry.Algo)
+	if err != nil {
+		return entry, errors.New("unknown checksum length")
+	}
+	entry.Sum, err = hex.DecodeString(sum)
+	if err != nil || len(entry.Sum) != h.Size() {
+		return entry, errors.New("invalid checksum")
+	}
+	return entry, nil
+}
+
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func unescapeChecksumPath(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// WriteChecksums 将校验信息按指定格式写入 w，文件名中的 "\"、换行符会按 coreutils 的规则转义
+func WriteChecksums(w io.Writer, entries []ChecksumEntry, format ChecksumFormat) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		path := filepath.ToSlash(e.Path)
+		prefix := ""
+		if strings.ContainsAny(path, "\\\n\r") {
+			prefix = "\\"
+			path = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r").Replace(path)
+		}
+		sum := hex.EncodeToString(e.Sum)
+		switch format {
+		case ChecksumGNU:
+			mode := " "
+			if e.Binary {
+				mode = "*"
+			}
+			fmt.Fprintf(bw, "%s%s %s%s\n", prefix, sum, mode, path)
+		case ChecksumBSD:
+			tag, ok := checksumBSDTags[e.Algo]
+			if !ok {
+				tag = strings.ToUpper(e.Algo.String())
+			}
+			fmt.Fprintf(bw, "%s%s (%s) = %s\n", prefix, tag, path, sum)
+		default:
+			return errors.New("unknown checksum format")
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadChecksumFile 读取校验文件
+// 算法优先根据文件名推断，如 SHA256SUMS、md5sum.txt、app.tar.gz.sha512，无法推断时根据哈希值的长度推断
+func ReadChecksumFile(filename string) ([]ChecksumEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseChecksums(f, checksumFileAlgo(filename))
+}
+
+// WriteChecksumFile 计算 files 的哈希值并写入校验文件，files 中的相对路径以校验文件所在目录为准
+func WriteChecksumFile(filename string, algo HashAlgo, files []string, format ChecksumFormat) error {
+	dir := filepath.Dir(filename)
+	entries := make([]ChecksumEntry, 0, len(files))
+	for _, file := range files {
+		sum, err := HashFile(algo, checksumPath(dir, file), true)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, ChecksumEntry{Algo: algo, Path: file, Sum: sum})
+	}
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		return WriteChecksums(w, entries, format)
+	})
+}
+
+// VerifyChecksumFile 校验文件中列出的所有文件，类似 sha256sum -c
+// 文件中的相对路径以校验文件所在目录为准；文件不存在时为 MISSING，哈希值不一致或读取失败时为 FAILED
+func VerifyChecksumFile(filename string) ([]ChecksumResult, error) {
+	entries, err := ReadChecksumFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(filename)
+	results := make([]ChecksumResult, 0, len(entries))
+	for _, e := range entries {
+		res := ChecksumResult{Path: e.Path, Status: ChecksumOK}
+		sum, err := HashFile(e.Algo, checksumPath(dir, e.Path), true)
+		if err != nil {
+			res.Status = ChecksumFailed
+			if os.IsNotExist(err) {
+				res.Status = ChecksumMissing
+			}
+			res.Err = err
+		} else if !hmac.Equal(sum, e.Sum) {
+			res.Status = ChecksumFailed
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func checksumPath(dir, path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// checksumFileAlgo 根据校验文件名推断算法，无法推断时返回 0
+func checksumFileAlgo(filename string) HashAlgo {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(filename)), ".txt")
+	if ext := filepath.Ext(name); ext != "" {
+		if algo, err := ParseHashAlgo(ext[1:]); err == nil {
+			return algo
+		}
+	}
+	for _, suffix := range []string{"sums", "sum"} {
+		if strings.HasSuffix(name, suffix) {
+			if algo, err := ParseHashAlgo(strings.TrimSuffix(name, suffix)); err == nil {
+				return algo
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package xutils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	helloMD5     = "5d41402abc4b2a76b9719d911017c592"
+	helloSHA256  = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	helloBLAKE2b = "e4cfa39a3d37be31c59609e807970799caa68a19bfaa15135f165085e01d41a65ba1e1b146aeb6bd0092b49eac214c103ccfa3a365954bbbe52f74a2b3620c94"
+)
+
+func TestParseChecksums(t *testing.T) {
+	text := "# comment\n" +
+		helloSHA256 + "  hello.txt\n" +
+		helloSHA256 + " *bin/hello.bin\r\n" +
+		"\n" +
+		"\\" + helloSHA256 + "  dir\\\\new\\nline.txt\n" +
+		helloSHA256 + "  name with (parens)\n" +
+		"MD5 (hello.txt) = " + helloMD5 + "\n" +
+		"BLAKE2b (a (1).txt) = " + helloBLAKE2b + "\n"
+	entries, err := ParseChecksums(strings.NewReader(text), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(entries))
+
+	assert.Equal(t, SHA256, entries[0].Algo)
+	assert.Equal(t, "hello.txt", entries[0].Path)
+	assert.Equal(t, helloSHA256, hex.EncodeToString(entries[0].Sum))
+	assert.False(t, entries[0].Binary)
+	assert.Equal(t, "bin/hello.bin", entries[1].Path)
+	assert.True(t, entries[1].Binary)
+	assert.Equal(t, "dir\\new\nline.txt", entries[2].Path)
+	assert.Equal(t, "name with (parens)", entries[3].Path)
+	assert.Equal(t, MD5, entries[4].Algo)
+	assert.Equal(t, helloMD5, hex.EncodeToString(entries[4].Sum))
+	assert.Equal(t, BLAKE2B_512, entries[5].Algo)
+	assert.Equal(t, "a (1).txt", entries[5].Path)
+
+	// 指定算法时，长度不一致的哈希值视为错误
+	_, err = ParseChecksums(strings.NewReader(helloMD5+"  hello.txt\n"), SHA256)
+	assert.NotNil(t, err)
+	for _, line := range []string{
+		"abc  hello.txt",
+		helloSHA256 + "  ",
+		helloSHA256 + " hello.txt",
+		"FOO (hello.txt) = " + helloMD5,
+		"MD5 (hello.txt) = zz",
+		"garbage",
+	} {
+		_, err = ParseChecksums(strings.NewReader(line), 0)
+		assert.NotNil(t, err, line)
+	}
+}
+
+func TestWriteChecksums(t *testing.T) {
+	sha, _ := hex.DecodeString(helloSHA256)
+	b2, _ := hex.DecodeString(helloBLAKE2b)
+	entries := []ChecksumEntry{
+		{Algo: SHA256, Path: "hello.txt", Sum: sha},
+		{Algo: SHA256, Path: "hello.bin", Sum: sha, Binary: true},
+		{Algo: SHA256, Path: "new\nline.txt", Sum: sha},
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, WriteChecksums(&buf, entries, ChecksumGNU))
+	assert.Equal(t, helloSHA256+"  hello.txt\n"+
+		helloSHA256+" *hello.bin\n"+
+		"\\"+helloSHA256+"  new\\nline.txt\n", buf.String())
+
+	parsed, err := ParseChecksums(&buf, SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, entries, parsed)
+
+	buf.Reset()
+	entries = []ChecksumEntry{
+		{Algo: SHA256, Path: "hello.txt", Sum: sha},
+		{Algo: BLAKE2B_512, Path: "hello.txt", Sum: b2},
+	}
+	assert.Nil(t, WriteChecksums(&buf, entries, ChecksumBSD))
+	assert.Equal(t, "SHA256 (hello.txt) = "+helloSHA256+"\n"+
+		"BLAKE2b (hello.txt) = "+helloBLAKE2b+"\n", buf.String())
+	parsed, err = ParseChecksums(&buf, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, entries, parsed)
+
+	assert.NotNil(t, WriteChecksums(&buf, entries, ChecksumFormat(9)))
+}
+
+func TestVerifyChecksumFile(t *testing.T) {
+	dir, clean := TempDir("checksum")
+	defer clean()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("world"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "c.txt"), []byte("c"), 0644))
+
+	sumFile := filepath.Join(dir, "SHA256SUMS")
+	assert.Nil(t, WriteChecksumFile(sumFile, SHA256, []string{"a.txt", "sub/b.txt", "c.txt"}, ChecksumGNU))
+	content, _ := os.ReadFile(sumFile)
+	assert.True(t, strings.HasPrefix(string(content), helloSHA256+"  a.txt\n"))
+
+	results, err := VerifyChecksumFile(sumFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	for _, r := range results {
+		assert.Equal(t, ChecksumOK, r.Status, r.Path)
+	}
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("changed"), 0644))
+	assert.Nil(t, os.Remove(filepath.Join(dir, "c.txt")))
+	results, err = VerifyChecksumFile(sumFile)
+	assert.Nil(t, err)
+	assert.Equal(t, ChecksumOK, results[0].Status)
+	assert.Equal(t, ChecksumFailed, results[1].Status)
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, ChecksumMissing, results[2].Status)
+	assert.NotNil(t, results[2].Err)
+	assert.Equal(t, "MISSING", results[2].Status.String())
+
+	// 根据文件名推断算法：sha256 与 blake2s-256 的长度相同
+	b2sFile := filepath.Join(dir, "files.blake2s")
+	assert.Nil(t, WriteChecksumFile(b2sFile, BLAKE2S_256, []string{"a.txt"}, ChecksumGNU))
+	results, err = VerifyChecksumFile(b2sFile)
+	assert.Nil(t, err)
+	assert.Equal(t, ChecksumOK, results[0].Status)
+
+	assert.NotNil(t, WriteChecksumFile(sumFile, SHA256, []string{"not_exists.txt"}, ChecksumGNU))
+	_, err = VerifyChecksumFile(filepath.Join(dir, "not_exists"))
+	assert.NotNil(t, err)
+}
+
+func TestChecksumFileAlgo(t *testing.T) {
+	assert.Equal(t, SHA256, checksumFileAlgo("/tmp/SHA256SUMS"))
+	assert.Equal(t, SHA512, checksumFileAlgo("sha512sums.txt"))
+	assert.Equal(t, MD5, checksumFileAlgo("md5sum.txt"))
+	assert.Equal(t, BLAKE2B_512, checksumFileAlgo("B2SUMS"))
+	assert.Equal(t, SHA1, checksumFileAlgo("app-1.0.tar.gz.sha1"))
+	assert.Equal(t, HashAlgo(0), checksumFileAlgo("checksums.txt"))
+}
@@ -0,0 +1,152 @@
+package xutils
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// Chunk 按内容切分出的数据块
+type Chunk struct {
+	Offset int64  // 在原始数据中的偏移
+	Length int    // 长度
+	Data   []byte // 数据，每个 Chunk 独立分配，可以安全保存
+	Sum    []byte // 数据的哈希值（原始字节）
+}
+
+// Chunker 使用 FastCDC 算法按内容定义的边界切分数据
+// 切分点只取决于附近的数据，在文件中插入或删除少量字节只会影响修改位置附近的块，适合去重存储、增量备份
+type Chunker struct {
+	Algo HashAlgo // 计算 Chunk.Sum 使用的算法，默认为 SHA256，需要在第一次调用 Next 之前设置
+
+	rd       io.Reader
+	min      int
+	avg      int
+	max      int
+	maskS    uint64
+	maskL    uint64
+	buf      []byte
+	offset   int64
+	eof      bool
+	finished bool
+}
+
+// gearTable FastCDC 使用的随机数表，由固定种子生成，保证不同进程、不同版本的切分结果一致
+var gearTable = func() [256]uint64 {
+	var t [256]uint64
+	seed := uint64(0x6a09e667f3bcc909)
+	for i := range t {
+		// splitmix64
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}()
+
+// ChunkReader 创建 FastCDC 切分器，min、avg、max 分别为块的最小、平均和最大长度
+// 需要满足 0 < min <= avg <= max，avg 会向下取整为2的幂，常用的参数为 2KiB、8KiB、64KiB
+func ChunkReader(rd io.Reader, min, avg, max int) (*Chunker, error) {
+	if min <= 0 || min > avg || avg > max {
+		return nil, errors.New("invalid chunk size")
+	}
+	// 使用归一化切分（normalized chunking）：平均长度之前使用更难满足的 maskS，之后使用更容易满足的 maskL，
+	// 让块的长度更集中在 avg 附近；使用高位作为掩码，使判断依赖于最近 64 个字节
+	n := bits.Len(uint(avg)) - 1
+	return &Chunker{
+		Algo:  SHA256,
+		rd:    rd,
+		min:   min,
+		avg:   avg,
+		max:   max,
+		maskS: chunkMask(n + 1),
+		maskL: chunkMask(n - 1),
+		buf:   make([]byte, 0, max),
+	}, nil
+}
+
+func chunkMask(n int) uint64 {
+	if n < 1 {
+		n = 1
+	}
+	if n > 63 {
+		n = 63
+	}
+	return ^uint64(0) << (64 - n)
+}
+
+// Next 返回下一个块，数据读取完毕时返回 io.EOF
+func (c *Chunker) Next() (*Chunk, error) {
+	if c.finished {
+		return nil, io.EOF
+	}
+	h, err := newHash(c.Algo)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.fill(); err != nil {
+		return nil, err
+	}
+	if len(c.buf) == 0 {
+		c.finished = true
+		return nil, io.EOF
+	}
+
+	n := c.cut(c.buf)
+	chunk := &Chunk{
+		Offset: c.offset,
+		Length: n,
+		Data:   append([]byte(nil), c.buf[:n]...),
+	}
+	h.Write(chunk.Data)
+	chunk.Sum = h.Sum(nil)
+	c.buf = c.buf[:copy(c.buf, c.buf[n:])]
+	c.offset += int64(n)
+	return chunk, nil
+}
+
+// fill 尽量将缓冲区填满到 max 字节
+func (c *Chunker) fill() error {
+	for !c.eof && len(c.buf) < c.max {
+		n, err := c.rd.Read(c.buf[len(c.buf):c.max])
+		c.buf = c.buf[:len(c.buf)+n]
+		if err == io.EOF {
+			c.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut 返回第一个块的长度
+func (c *Chunker) cut(b []byte) int {
+	size := len(b)
+	if size <= c.min {
+		return size
+	}
+	if size > c.max {
+		size = c.max
+	}
+	center := c.avg
+	if size < center {
+		center = size
+	}
+	var fp uint64
+	i := c.min
+	for ; i < center; i++ {
+		fp = (fp << 1) + gearTable[b[i]]
+		if fp&c.maskS == 0 {
+			return i
+		}
+	}
+	for ; i < size; i++ {
+		fp = (fp << 1) + gearTable[b[i]]
+		if fp&c.maskL == 0 {
+			return i
+		}
+	}
+	return size
+}
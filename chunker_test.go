@@ -0,0 +1,109 @@
+package xutils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func readChunks(t *testing.T, data []byte, min, avg, max int) []*Chunk {
+	c, err := ChunkReader(bytes.NewReader(data), min, avg, max)
+	assert.Nil(t, err)
+	chunks := make([]*Chunk, 0)
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestChunkReader(t *testing.T) {
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	chunks := readChunks(t, data, 2048, 8192, 65536)
+	var (
+		joined []byte
+		offset int64
+	)
+	for i, c := range chunks {
+		assert.Equal(t, offset, c.Offset)
+		assert.Equal(t, len(c.Data), c.Length)
+		assert.LessOrEqual(t, c.Length, 65536)
+		if i < len(chunks)-1 {
+			assert.GreaterOrEqual(t, c.Length, 2048)
+		}
+		sum := sha256.Sum256(c.Data)
+		assert.Equal(t, sum[:], c.Sum)
+		joined = append(joined, c.Data...)
+		offset += int64(c.Length)
+	}
+	assert.Equal(t, data, joined)
+	// 平均长度接近 avg
+	avg := len(data) / len(chunks)
+	assert.InDelta(t, 8192, avg, 8192*0.3, "avg %d", avg)
+
+	// 结果是确定的
+	again := readChunks(t, data, 2048, 8192, 65536)
+	assert.Equal(t, chunks, again)
+}
+
+func TestChunkReaderShift(t *testing.T) {
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(2)).Read(data)
+	modified := append([]byte("inserted"), data...)
+
+	sums := make(map[string]bool)
+	for _, c := range readChunks(t, data, 2048, 8192, 65536) {
+		sums[string(c.Sum)] = true
+	}
+	chunks := readChunks(t, modified, 2048, 8192, 65536)
+	changed := 0
+	for _, c := range chunks {
+		if !sums[string(c.Sum)] {
+			changed++
+		}
+	}
+	// 在开头插入数据只影响最前面的少数块
+	assert.LessOrEqual(t, changed, 3)
+	assert.Greater(t, len(chunks), 50)
+}
+
+func TestChunkReaderEdge(t *testing.T) {
+	_, err := ChunkReader(bytes.NewReader(nil), 0, 8, 16)
+	assert.NotNil(t, err)
+	_, err = ChunkReader(bytes.NewReader(nil), 32, 8, 16)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 0, len(readChunks(t, nil, 64, 256, 1024)))
+	chunks := readChunks(t, []byte("short"), 64, 256, 1024)
+	assert.Equal(t, 1, len(chunks))
+	assert.Equal(t, []byte("short"), chunks[0].Data)
+
+	// 全部相同的数据找不到切分点，按 max 切分
+	chunks = readChunks(t, make([]byte, 5000), 64, 256, 1024)
+	assert.Equal(t, 5, len(chunks))
+	assert.Equal(t, 1024, chunks[0].Length)
+	assert.Equal(t, 904, chunks[4].Length)
+
+	c, _ := ChunkReader(bytes.NewReader([]byte("data")), 64, 256, 1024)
+	c.Algo = MD5
+	chunk, err := c.Next()
+	assert.Nil(t, err)
+	sum, _ := Hash(MD5, []byte("data"), true)
+	assert.Equal(t, sum, chunk.Sum)
+	_, err = c.Next()
+	assert.Equal(t, io.EOF, err)
+
+	c, _ = ChunkReader(bytes.NewReader([]byte("data")), 64, 256, 1024)
+	c.Algo = HashAlgo(99)
+	_, err = c.Next()
+	assert.NotNil(t, err)
+}
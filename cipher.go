@@ -7,18 +7,85 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
 	"io"
 )
 
+var (
+	// ErrAuthFailed 密文签名或认证标签校验失败，密钥错误或密文被篡改
+	ErrAuthFailed = errors.New("cipher: message authentication failed")
+	// ErrInvalidCiphertext 密文格式或长度不正确
+	ErrInvalidCiphertext = errors.New("cipher: invalid cipher text")
+	// ErrInvalidPadding 解密后的填充不正确
+	ErrInvalidPadding = errors.New("cipher: invalid padding")
+)
+
+// KDF 密钥派生算法
+type KDF uint8
+
+const (
+	// HKDF 适用于高熵的密钥（如随机生成的32字节密钥），速度快
+	HKDF KDF = 1 + iota
+	// PBKDF2 适用于用户密码等低熵密钥，使用 PBKDF2-HMAC-SHA256 进行密钥拉伸
+	PBKDF2
+	// Scrypt 适用于用户密码等低熵密钥，内存困难型的密钥拉伸
+	Scrypt
+)
+
+const (
+	cipherMagic    = 0xA5
+	cipherVersion1 = 1
+	cipherSaltSize = 16
+
+	pbkdf2Iterations = 600000
+	scryptLogN       = 15
+	scryptR          = 8
+	scryptP          = 1
+)
+
+// 解密时允许的最大KDF参数。KDF参数来自密文头部，且在签名校验之前就要派生密钥，
+// 不加限制时构造的密文可以消耗大量CPU和内存。默认与加密时使用的参数相同，
+// 需要解密其他程序使用更高参数生成的密文时可以调大
+var (
+	MaxPBKDF2Iterations uint32 = pbkdf2Iterations
+	MaxScryptLogN       uint8  = scryptLogN
+	MaxScryptR          uint8  = scryptR
+	MaxScryptP          uint8  = scryptP
+)
+
 // AesEncrypt AES加密
-// text 为要加密的内容，key 为密钥，内部使用md5转为32字节的密钥（AES-256）
-// 结果密文中，包含了16字节的CBC模式初始向量和32字节的HMAC-SHA256签名
-// 结果: 16字节初始向量 + 密文 + 32字节签名
+// text 为要加密的内容，key 为密钥，使用 HKDF 从 key 派生出加密子密钥和签名子密钥
+// 使用 AES-256-CBC 加密，HMAC-SHA256 对头部和密文进行签名
+// 结果格式见 AesEncryptWithKDF
 func AesEncrypt(text []byte, key []byte) ([]byte, error) {
-	key, _ = Hash(MD5, key, false)
+	return AesEncryptWithKDF(text, key, HKDF)
+}
 
-	block, err := aes.NewCipher(key)
+// AesEncryptWithKDF 使用指定的密钥派生算法进行AES加密
+// key 为用户密码时应使用 PBKDF2 或 Scrypt
+// 结果: 1字节标识(0xA5) + 1字节版本号 + 1字节KDF + KDF参数 + 16字节盐 + 16字节CBC初始向量 + 密文 + 32字节签名
+// KDF参数: HKDF 无参数；PBKDF2 为4字节迭代次数（大端）；Scrypt 为 log2(N)、r、p 各1字节
+func AesEncryptWithKDF(text []byte, key []byte, kdf KDF) ([]byte, error) {
+	header, err := newCipherHeader(kdf)
+	if err != nil {
+		return nil, err
+	}
+	salt := header[len(header)-cipherSaltSize:]
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	encKey, macKey, err := deriveCipherKeys(header, key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(encKey)
 	if err != nil {
 		return nil, err
 	}
@@ -27,29 +94,65 @@ func AesEncrypt(text []byte, key []byte) ([]byte, error) {
 	blockSize := block.BlockSize()
 	text = pkcs5Padding(text, blockSize)
 
-	// 第一个 blockSize 用于保存随机向量
-	cipherText := make([]byte, blockSize+len(text))
-	iv := cipherText[:blockSize]
+	// 头部之后的第一个 blockSize 用于保存随机向量
+	cipherText := make([]byte, len(header)+blockSize+len(text), len(header)+blockSize+len(text)+sha256.Size)
+	copy(cipherText, header)
+	iv := cipherText[len(header) : len(header)+blockSize]
 	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
 		return nil, err
 	}
 
 	blockMode := cipher.NewCBCEncrypter(block, iv)
-	blockMode.CryptBlocks(cipherText[blockSize:], text)
+	blockMode.CryptBlocks(cipherText[len(header)+blockSize:], text)
 
 	// 末尾加上签名
-	h := hmac.New(sha256.New, key)
-	_, err = h.Write(cipherText)
-	if err != nil {
-		return nil, err
-	}
-
+	h := hmac.New(sha256.New, macKey)
+	h.Write(cipherText)
 	return h.Sum(cipherText), nil
 }
 
 // AesDecrypt AES解密
 // cipherText 密文，key 为加密使用的密钥
+// 同时支持旧版本格式（16字节初始向量 + 密文 + 32字节签名，使用md5(key)作为密钥）的密文，
+// 旧版本密文长度总是块大小的倍数，而新格式不是，以此区分两种格式
 func AesDecrypt(cipherText []byte, key []byte) ([]byte, error) {
+	if len(cipherText)%aes.BlockSize == 0 {
+		return aesDecryptLegacy(cipherText, key)
+	}
+
+	headerSize, err := cipherHeaderSize(cipherText)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherText) < headerSize+aes.BlockSize*2+sha256.Size {
+		return nil, ErrInvalidCiphertext
+	}
+	if err = checkCipherKDFParams(cipherText[:headerSize]); err != nil {
+		return nil, err
+	}
+	encKey, macKey, err := deriveCipherKeys(cipherText[:headerSize], key)
+	if err != nil {
+		return nil, err
+	}
+
+	// 签名校验
+	sign := cipherText[len(cipherText)-sha256.Size:]
+	cipherText = cipherText[:len(cipherText)-sha256.Size]
+	h := hmac.New(sha256.New, macKey)
+	h.Write(cipherText)
+	if !hmac.Equal(sign, h.Sum(nil)) {
+		return nil, ErrAuthFailed
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return cbcDecrypt(block, cipherText[headerSize:])
+}
+
+// aesDecryptLegacy 解密旧版本格式的密文
+func aesDecryptLegacy(cipherText []byte, key []byte) ([]byte, error) {
 	key, _ = Hash(MD5, key, false)
 
 	block, err := aes.NewCipher(key)
@@ -59,7 +162,7 @@ func AesDecrypt(cipherText []byte, key []byte) ([]byte, error) {
 	blockSize := block.BlockSize()
 
 	if len(cipherText) < (sha256.Size + blockSize) {
-		return nil, errors.New("cipher text invalid")
+		return nil, ErrInvalidCiphertext
 	}
 
 	// 签名校验
@@ -73,9 +176,18 @@ func AesDecrypt(cipherText []byte, key []byte) ([]byte, error) {
 	}
 
 	if !hmac.Equal(sign, h.Sum(nil)) {
-		return nil, errors.New("sign error")
+		return nil, ErrAuthFailed
 	}
 
+	return cbcDecrypt(block, cipherText)
+}
+
+// cbcDecrypt CBC模式解密，cipherText 为初始向量 + 密文
+func cbcDecrypt(block cipher.Block, cipherText []byte) ([]byte, error) {
+	blockSize := block.BlockSize()
+	if len(cipherText) < blockSize*2 || len(cipherText)%blockSize != 0 {
+		return nil, ErrInvalidCiphertext
+	}
 	iv := cipherText[:blockSize]
 	cipherText = cipherText[blockSize:]
 	text := make([]byte, len(cipherText))
@@ -83,18 +195,204 @@ func AesDecrypt(cipherText []byte, key []byte) ([]byte, error) {
 	mode := cipher.NewCBCDecrypter(block, iv)
 	mode.CryptBlocks(text, cipherText)
 
-	text = pkcs5UnPadding(text)
+	return pkcs5UnPadding(text, blockSize)
+}
+
+// newCipherHeader 生成密文头部，末尾的盐需要调用方填充
+func newCipherHeader(kdf KDF) ([]byte, error) {
+	header := []byte{cipherMagic, cipherVersion1, byte(kdf)}
+	switch kdf {
+	case HKDF:
+	case PBKDF2:
+		header = append(header, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(header[3:], pbkdf2Iterations)
+	case Scrypt:
+		header = append(header, scryptLogN, scryptR, scryptP)
+	default:
+		return nil, errors.New("unknown kdf")
+	}
+	return append(header, make([]byte, cipherSaltSize)...), nil
+}
+
+// cipherHeaderSize 解析密文头部，返回头部长度
+func cipherHeaderSize(cipherText []byte) (int, error) {
+	if len(cipherText) < 3 || cipherText[0] != cipherMagic {
+		return 0, ErrInvalidCiphertext
+	}
+	if cipherText[1] != cipherVersion1 {
+		return 0, fmt.Errorf("%w: unsupported version", ErrInvalidCiphertext)
+	}
+	size := 3 + cipherSaltSize
+	switch KDF(cipherText[2]) {
+	case HKDF:
+	case PBKDF2:
+		size += 4
+	case Scrypt:
+		size += 3
+	default:
+		return 0, fmt.Errorf("%w: unknown kdf", ErrInvalidCiphertext)
+	}
+	if len(cipherText) < size {
+		return 0, ErrInvalidCiphertext
+	}
+	return size, nil
+}
+
+// checkCipherKDFParams 检查密文头部中的KDF参数是否超过 MaxPBKDF2Iterations、MaxScryptLogN 等限制
+func checkCipherKDFParams(header []byte) error {
+	params := header[3 : len(header)-cipherSaltSize]
+	switch KDF(header[2]) {
+	case PBKDF2:
+		iter := binary.BigEndian.Uint32(params)
+		if iter == 0 || iter > MaxPBKDF2Iterations {
+			return fmt.Errorf("%w: kdf params exceed limit", ErrInvalidCiphertext)
+		}
+	case Scrypt:
+		logN, r, p := params[0], params[1], params[2]
+		if logN == 0 || r == 0 || p == 0 || logN > MaxScryptLogN || r > MaxScryptR || p > MaxScryptP {
+			return fmt.Errorf("%w: kdf params exceed limit", ErrInvalidCiphertext)
+		}
+	}
+	return nil
+}
+
+// deriveCipherKeys 根据头部中的KDF参数和盐，从 key 派生出32字节的加密子密钥和32字节的签名子密钥
+func deriveCipherKeys(header []byte, key []byte) (encKey []byte, macKey []byte, err error) {
+	salt := header[len(header)-cipherSaltSize:]
+	params := header[3 : len(header)-cipherSaltSize]
+	var master []byte
+	switch KDF(header[2]) {
+	case HKDF:
+		master = make([]byte, 64)
+		_, err = io.ReadFull(hkdf.New(sha256.New, key, salt, []byte("xutils aes-cbc-hmac-sha256")), master)
+	case PBKDF2:
+		master = pbkdf2.Key(key, salt, int(binary.BigEndian.Uint32(params)), 64, sha256.New)
+	case Scrypt:
+		master, err = scrypt.Key(key, salt, 1<<params[0], int(params[1]), int(params[2]), 64)
+	default:
+		err = errors.New("unknown kdf")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return master[:32], master[32:], nil
+}
+
+// AEAD 基于 AES-GCM 的认证加密，可重复使用
+// 密文格式: 12字节随机nonce + 密文 + 16字节认证标签
+type AEAD struct {
+	aead cipher.AEAD
+}
+
+// NewAEAD 创建 AES-GCM 认证加密对象
+// key 长度必须为16、24或32字节，分别对应 AES-128、AES-192、AES-256
+func NewAEAD(key []byte) (*AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AEAD{aead: aead}, nil
+}
+
+// Overhead 返回密文相对明文增加的长度
+func (a *AEAD) Overhead() int {
+	return a.aead.NonceSize() + a.aead.Overhead()
+}
+
+// Seal 加密并认证 text，additionalData 为可选的关联数据，
+// 关联数据不会被加密，但解密时必须提供相同的值
+func (a *AEAD) Seal(text []byte, additionalData []byte) ([]byte, error) {
+	nonceSize := a.aead.NonceSize()
+	out := make([]byte, nonceSize, nonceSize+len(text)+a.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, out); err != nil {
+		return nil, err
+	}
+	return a.aead.Seal(out, out, text, additionalData), nil
+}
+
+// Open 解密并校验 Seal 生成的密文
+func (a *AEAD) Open(cipherText []byte, additionalData []byte) ([]byte, error) {
+	nonceSize := a.aead.NonceSize()
+	if len(cipherText) < nonceSize+a.aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
+	text, err := a.aead.Open(nil, nonce, cipherText, additionalData)
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
 	return text, nil
 }
 
+// AesGCMEncrypt AES-GCM 认证加密
+// key 长度必须为16、24或32字节，additionalData 为可选的关联数据（如用户ID、记录ID），
+// 用于将密文绑定到指定的上下文，解密时必须提供相同的值，最多只能传入一个，多个值需要由调用方自行拼接
+// 结果: 12字节随机nonce + 密文 + 16字节认证标签
+func AesGCMEncrypt(text []byte, key []byte, additionalData ...[]byte) ([]byte, error) {
+	a, err := NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := gcmAdditionalData(additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return a.Seal(text, ad)
+}
+
+// AesGCMDecrypt AES-GCM 解密
+// cipherText 为 AesGCMEncrypt 生成的密文，key 和 additionalData 必须与加密时一致
+func AesGCMDecrypt(cipherText []byte, key []byte, additionalData ...[]byte) ([]byte, error) {
+	a, err := NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := gcmAdditionalData(additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return a.Open(cipherText, ad)
+}
+
+// gcmAdditionalData 返回可选的关联数据，传入多个时返回错误，避免后面的值被忽略
+func gcmAdditionalData(b [][]byte) ([]byte, error) {
+	switch len(b) {
+	case 0:
+		return nil, nil
+	case 1:
+		return b[0], nil
+	}
+	return nil, errors.New("too many additional data arguments")
+}
+
+// pkcs5Padding 返回填充后的新slice，不会修改 text 的底层数组
 func pkcs5Padding(text []byte, blockSize int) []byte {
 	padding := blockSize - len(text)%blockSize
-	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(text, padText...)
+	padText := make([]byte, len(text), len(text)+padding)
+	copy(padText, text)
+	return append(padText, bytes.Repeat([]byte{byte(padding)}, padding)...)
 }
 
-func pkcs5UnPadding(cipherText []byte) []byte {
-	length := len(cipherText)
-	unPadding := int(cipherText[length-1])
-	return cipherText[:(length - unPadding)]
+// pkcs5UnPadding 移除并校验填充，校验过程与填充内容无关，耗时恒定
+func pkcs5UnPadding(text []byte, blockSize int) ([]byte, error) {
+	length := len(text)
+	if length < blockSize || length%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	padding := int(text[length-1])
+	good := subtle.ConstantTimeLessOrEq(1, padding) & subtle.ConstantTimeLessOrEq(padding, blockSize)
+	for i := 1; i <= blockSize; i++ {
+		// 最后 padding 个字节必须都等于 padding
+		inPadding := subtle.ConstantTimeLessOrEq(i, padding)
+		equal := subtle.ConstantTimeByteEq(text[length-i], byte(padding))
+		good &= subtle.ConstantTimeSelect(inPadding, equal, 1)
+	}
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return text[:length-padding], nil
 }
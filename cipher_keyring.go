@@ -0,0 +1,223 @@
+package xutils
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const cipherVersionKeyring = 3
+
+var (
+	ErrUnknownKeyID = errors.New("unknown key id")
+	ErrNoActiveKey  = errors.New("no active key")
+)
+
+// Keyring 保存多个带ID的密钥，其中一个为当前使用的密钥，用于密钥轮换
+// 加密时使用当前密钥并在密文中记录密钥ID，解密时根据密钥ID选择对应的密钥
+// 密文格式: 1字节标识(0xA5) + 1字节版本号(3) + 1字节密钥ID长度 + 密钥ID + AesEncrypt 密文
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[string][]byte
+	active string
+}
+
+// keyringConfig Keyring 的JSON配置格式，密钥使用标准base64编码
+//
+//	{"active": "2024q2", "keys": {"2024q1": "c2VjcmV0MQ==", "2024q2": "c2VjcmV0Mg=="}}
+type keyringConfig struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// NewKeyring 创建一个空的 Keyring
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string][]byte)}
+}
+
+// LoadKeyring 从JSON配置中加载 Keyring
+func LoadKeyring(data []byte) (*Keyring, error) {
+	var conf keyringConfig
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+	kr := NewKeyring()
+	for id, s := range conf.Keys {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		if err = kr.Add(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if err := kr.SetActive(conf.Active); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+// LoadKeyringFromEnv 从环境变量中加载JSON格式的 Keyring 配置
+func LoadKeyringFromEnv(name string) (*Keyring, error) {
+	data, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s not set", name)
+	}
+	return LoadKeyring([]byte(data))
+}
+
+// Add 添加密钥，如果ID已存在则替换，ID长度为1-255字节
+func (kr *Keyring) Add(id string, key []byte) error {
+	if len(id) == 0 || len(id) > 255 {
+		return errors.New("invalid key id")
+	}
+	if len(key) == 0 {
+		return errors.New("empty key")
+	}
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.keys[id] = append([]byte{}, key...)
+	return nil
+}
+
+// Remove 删除密钥，不能删除当前使用的密钥
+func (kr *Keyring) Remove(id string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if id == kr.active {
+		return errors.New("can not remove active key")
+	}
+	delete(kr.keys, id)
+	return nil
+}
+
+// SetActive 设置加密使用的密钥
+func (kr *Keyring) SetActive(id string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[id]; !ok {
+		return ErrUnknownKeyID
+	}
+	kr.active = id
+	return nil
+}
+
+// Active 返回当前使用的密钥ID
+func (kr *Keyring) Active() string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return kr.active
+}
+
+// IDs 返回所有密钥ID
+func (kr *Keyring) IDs() []string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	ids := make([]string, 0, len(kr.keys))
+	for id := range kr.keys {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Encrypt 使用当前密钥加密，密文中包含密钥ID
+func (kr *Keyring) Encrypt(text []byte) ([]byte, error) {
+	kr.mu.RLock()
+	id, key := kr.active, kr.keys[kr.active]
+	kr.mu.RUnlock()
+	if key == nil {
+		return nil, ErrNoActiveKey
+	}
+	cipherText, err := AesEncrypt(text, key)
+	if err != nil {
+		return nil, err
+	}
+	header := []byte{cipherMagic, cipherVersionKeyring, byte(len(id))}
+	return append(append(header, id...), cipherText...), nil
+}
+
+// Decrypt 根据密文中的密钥ID选择密钥解密
+// 对于不包含密钥ID的密文（直接由 AesEncrypt 生成），依次尝试所有密钥；
+// 使用 PBKDF2、Scrypt 加密的密文每次尝试都要进行一次密钥拉伸，开销很大，只尝试当前密钥
+func (kr *Keyring) Decrypt(cipherText []byte) ([]byte, error) {
+	text, _, err := kr.decrypt(cipherText)
+	return text, err
+}
+
+// KeyID 返回密文中记录的密钥ID
+func (kr *Keyring) KeyID(cipherText []byte) (string, error) {
+	id, _, ok := parseKeyringHeader(cipherText)
+	if !ok {
+		return "", ErrInvalidCiphertext
+	}
+	return id, nil
+}
+
+// Reencrypt 将使用旧密钥加密的密文用当前密钥重新加密
+// 如果密文已经使用当前密钥加密，原样返回
+func (kr *Keyring) Reencrypt(cipherText []byte) ([]byte, error) {
+	text, id, err := kr.decrypt(cipherText)
+	if err != nil {
+		return nil, err
+	}
+	if id == kr.Active() {
+		return cipherText, nil
+	}
+	return kr.Encrypt(text)
+}
+
+// decrypt 解密并返回使用的密钥ID，对于不包含密钥ID的密文，返回的ID为空
+func (kr *Keyring) decrypt(cipherText []byte) ([]byte, string, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	id, body, ok := parseKeyringHeader(cipherText)
+	if ok {
+		key, found := kr.keys[id]
+		if !found {
+			return nil, "", ErrUnknownKeyID
+		}
+		text, err := AesDecrypt(body, key)
+		return text, id, err
+	}
+
+	// 旧数据不包含密钥ID，优先尝试当前密钥
+	if key, found := kr.keys[kr.active]; found {
+		if text, err := AesDecrypt(cipherText, key); err == nil {
+			return text, "", nil
+		}
+	}
+	if !keyringTryAllKeys(cipherText) {
+		return nil, "", ErrAuthFailed
+	}
+	for kid, key := range kr.keys {
+		if kid == kr.active {
+			continue
+		}
+		if text, err := AesDecrypt(cipherText, key); err == nil {
+			return text, "", nil
+		}
+	}
+	return nil, "", ErrAuthFailed
+}
+
+// keyringTryAllKeys 不包含密钥ID的密文是否可以依次尝试所有密钥
+// 只有旧格式和使用 HKDF 的密文派生密钥的开销很小，PBKDF2、Scrypt 的开销会随密钥数量成倍增加
+func keyringTryAllKeys(cipherText []byte) bool {
+	return len(cipherText)%aes.BlockSize == 0 || (len(cipherText) > 2 && KDF(cipherText[2]) == HKDF)
+}
+
+func parseKeyringHeader(cipherText []byte) (id string, body []byte, ok bool) {
+	if len(cipherText) < 3 || cipherText[0] != cipherMagic || cipherText[1] != cipherVersionKeyring {
+		return "", nil, false
+	}
+	n := int(cipherText[2])
+	if n == 0 || len(cipherText) < 3+n {
+		return "", nil, false
+	}
+	return string(cipherText[3 : 3+n]), cipherText[3+n:], true
+}
@@ -0,0 +1,111 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	kr := NewKeyring()
+	_, err := kr.Encrypt([]byte("hello"))
+	assert.Equal(t, ErrNoActiveKey, err)
+
+	assert.Nil(t, kr.Add("k1", []byte("secret1")))
+	assert.Nil(t, kr.SetActive("k1"))
+	assert.Equal(t, ErrUnknownKeyID, kr.SetActive("k0"))
+
+	c1, err := kr.Encrypt([]byte("hello"))
+	assert.Nil(t, err)
+	id, err := kr.KeyID(c1)
+	assert.Nil(t, err)
+	assert.Equal(t, "k1", id)
+
+	// 轮换密钥
+	assert.Nil(t, kr.Add("k2", []byte("secret2")))
+	assert.Nil(t, kr.SetActive("k2"))
+	assert.NotNil(t, kr.Remove("k2"))
+
+	text, err := kr.Decrypt(c1)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(text))
+
+	c2, err := kr.Reencrypt(c1)
+	assert.Nil(t, err)
+	id, _ = kr.KeyID(c2)
+	assert.Equal(t, "k2", id)
+	c3, err := kr.Reencrypt(c2)
+	assert.Nil(t, err)
+	assert.Equal(t, c2, c3)
+
+	// 删除旧密钥后无法解密旧密文
+	assert.Nil(t, kr.Remove("k1"))
+	_, err = kr.Decrypt(c1)
+	assert.Equal(t, ErrUnknownKeyID, err)
+	text, err = kr.Decrypt(c2)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(text))
+}
+
+func TestKeyringLegacy(t *testing.T) {
+	kr := NewKeyring()
+	kr.Add("old", []byte("old secret"))
+	kr.Add("new", []byte("new secret"))
+	kr.SetActive("new")
+
+	// 不带密钥ID的密文
+	c, _ := AesEncrypt([]byte("hello"), []byte("old secret"))
+	text, err := kr.Decrypt(c)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(text))
+
+	c2, err := kr.Reencrypt(c)
+	assert.Nil(t, err)
+	id, _ := kr.KeyID(c2)
+	assert.Equal(t, "new", id)
+
+	c, _ = AesEncrypt([]byte("hello"), []byte("other"))
+	_, err = kr.Decrypt(c)
+	assert.NotNil(t, err)
+
+	// 使用 PBKDF2、Scrypt 的密文只尝试当前密钥
+	c, _ = AesEncryptWithKDF([]byte("hello"), []byte("new secret"), Scrypt)
+	text, err = kr.Decrypt(c)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(text))
+	c, _ = AesEncryptWithKDF([]byte("hello"), []byte("old secret"), Scrypt)
+	_, err = kr.Decrypt(c)
+	assert.Equal(t, ErrAuthFailed, err)
+
+	// 未知的密钥ID直接返回错误，不会再尝试其他密钥
+	c, _ = kr.Encrypt([]byte("hello"))
+	c[3] = 'x'
+	_, err = kr.Decrypt(c)
+	assert.Equal(t, ErrUnknownKeyID, err)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	conf := `{"active": "2024q2", "keys": {"2024q1": "c2VjcmV0MQ==", "2024q2": "c2VjcmV0Mg=="}}`
+	kr, err := LoadKeyring([]byte(conf))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024q2", kr.Active())
+	assert.ElementsMatch(t, []string{"2024q1", "2024q2"}, kr.IDs())
+
+	c, _ := AesEncrypt([]byte("hello"), []byte("secret1"))
+	text, err := kr.Decrypt(c)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(text))
+
+	os.Setenv("XUTILS_TEST_KEYRING", conf)
+	defer os.Unsetenv("XUTILS_TEST_KEYRING")
+	kr, err = LoadKeyringFromEnv("XUTILS_TEST_KEYRING")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024q2", kr.Active())
+
+	_, err = LoadKeyringFromEnv("XUTILS_TEST_KEYRING_NOT_EXISTS")
+	assert.NotNil(t, err)
+	_, err = LoadKeyring([]byte(`{"active": "x", "keys": {"2024q1": "c2VjcmV0MQ=="}}`))
+	assert.Equal(t, ErrUnknownKeyID, err)
+	_, err = LoadKeyring([]byte(`{"active": "k", "keys": {"k": "!!"}}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,272 @@
+package xutils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"os"
+)
+
+const (
+	cipherVersionStream = 2
+
+	// streamChunkSize 每个分段的明文长度
+	streamChunkSize = 64 * 1024
+	// streamNoncePrefixSize nonce前缀长度，nonce = 7字节前缀 + 4字节分段序号 + 1字节结束标记
+	streamNoncePrefixSize = 7
+	streamHeaderSize      = 3 + cipherSaltSize + streamNoncePrefixSize
+)
+
+// NewEncryptWriter 返回一个加密 Writer，写入的数据加密后写入 w，适用于大文件等无法一次性载入内存的数据
+// 数据按64KB分段，每段使用 AES-256-GCM 单独加密认证，最后一段带有结束标记，可以防止密文被截断；
+// 写入完成后必须调用 Close 写入最后一段，Close 不会关闭 w
+// 结果: 1字节标识(0xA5) + 1字节版本号(2) + 1字节KDF(HKDF) + 16字节盐 + 7字节nonce前缀 + 分段密文...
+// 每个分段为 密文 + 16字节认证标签
+func NewEncryptWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	header := make([]byte, streamHeaderSize)
+	header[0], header[1], header[2] = cipherMagic, cipherVersionStream, byte(HKDF)
+	if _, err := io.ReadFull(rand.Reader, header[3:]); err != nil {
+		return nil, err
+	}
+	aead, err := newStreamAEAD(header, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+	sw := &encryptWriter{
+		w:   w,
+		buf: make([]byte, 0, streamChunkSize),
+		out: make([]byte, 0, streamChunkSize+aead.Overhead()),
+	}
+	sw.stream.init(aead, header)
+	return sw, nil
+}
+
+// NewDecryptReader 返回一个解密 Reader，从 r 中读取 NewEncryptWriter 生成的密文并解密
+// 每个分段在认证通过后才会返回数据，密文被截断、篡改或有多余数据时返回错误
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrInvalidCiphertext
+		}
+		return nil, err
+	}
+	if header[0] != cipherMagic || header[1] != cipherVersionStream {
+		return nil, ErrInvalidCiphertext
+	}
+	if KDF(header[2]) != HKDF {
+		return nil, fmt.Errorf("%w: unknown kdf", ErrInvalidCiphertext)
+	}
+	aead, err := newStreamAEAD(header, key)
+	if err != nil {
+		return nil, err
+	}
+	sr := &decryptReader{
+		r:   r,
+		buf: make([]byte, streamChunkSize+aead.Overhead()+1),
+	}
+	sr.stream.init(aead, header)
+	return sr, nil
+}
+
+// EncryptFile 加密文件 src 并保存到 dst
+// 先写入临时文件，完成后再重命名为 dst，不会留下不完整的目标文件
+func EncryptFile(src string, dst string, key []byte) error {
+	fr, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+	return writeFileAtomic(dst, func(w io.Writer) error {
+		ew, err := NewEncryptWriter(w, key)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(ew, fr); err != nil {
+			return err
+		}
+		return ew.Close()
+	})
+}
+
+// DecryptFile 解密 EncryptFile 生成的文件 src 并保存到 dst
+// 只有全部数据解密并认证成功后才会生成 dst，dst 的权限为 OwnerOnlyFileMode
+func DecryptFile(src string, dst string, key []byte) error {
+	fr, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+	return writeFileAtomicMode(dst, OwnerOnlyFileMode, func(w io.Writer) error {
+		dr, err := NewDecryptReader(fr, key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, dr)
+		return err
+	})
+}
+
+func newStreamAEAD(header []byte, key []byte) (cipher.AEAD, error) {
+	salt := header[3 : 3+cipherSaltSize]
+	encKey := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, []byte("xutils aes-gcm stream")), encKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// stream 分段加密的公共状态
+type stream struct {
+	aead    cipher.AEAD
+	header  []byte // 作为每个分段的关联数据
+	nonce   [12]byte
+	counter uint32
+}
+
+func (s *stream) init(aead cipher.AEAD, header []byte) {
+	s.aead = aead
+	s.header = header
+	copy(s.nonce[:], header[3+cipherSaltSize:])
+}
+
+// next 返回下一个分段使用的nonce
+func (s *stream) next(last bool) ([]byte, error) {
+	if s.counter == ^uint32(0) {
+		return nil, errors.New("stream too large")
+	}
+	binary.BigEndian.PutUint32(s.nonce[streamNoncePrefixSize:], s.counter)
+	s.nonce[11] = 0
+	if last {
+		s.nonce[11] = 1
+	}
+	s.counter++
+	return s.nonce[:], nil
+}
+
+type encryptWriter struct {
+	stream
+	w      io.Writer
+	buf    []byte // 待加密的明文
+	out    []byte
+	err    error
+	closed bool
+}
+
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	if ew.closed {
+		return 0, errors.New("write to closed writer")
+	}
+	n := 0
+	for len(p) > 0 {
+		// 缓冲区已满且还有数据，说明当前分段不是最后一段
+		if len(ew.buf) == cap(ew.buf) {
+			if ew.err = ew.flush(false); ew.err != nil {
+				return n, ew.err
+			}
+		}
+		m := copy(ew.buf[len(ew.buf):cap(ew.buf)], p)
+		ew.buf = ew.buf[:len(ew.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+// Close 加密并写入最后一段，不会关闭底层的 Writer
+func (ew *encryptWriter) Close() error {
+	if ew.err != nil || ew.closed {
+		return ew.err
+	}
+	ew.closed = true
+	ew.err = ew.flush(true)
+	return ew.err
+}
+
+func (ew *encryptWriter) flush(last bool) error {
+	nonce, err := ew.next(last)
+	if err != nil {
+		return err
+	}
+	ew.out = ew.aead.Seal(ew.out[:0], nonce, ew.buf, ew.header)
+	ew.buf = ew.buf[:0]
+	_, err = ew.w.Write(ew.out)
+	return err
+}
+
+type decryptReader struct {
+	stream
+	r          io.Reader
+	buf        []byte // 分段密文 + 下一段的第一个字节
+	plain      []byte // 已解密未读取的数据
+	pending    byte
+	hasPending bool
+	done       bool
+	err        error
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.plain) == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		if dr.done {
+			return 0, io.EOF
+		}
+		dr.err = dr.readChunk()
+	}
+	n := copy(p, dr.plain)
+	dr.plain = dr.plain[n:]
+	return n, nil
+}
+
+// readChunk 读取并解密下一个分段
+// 多读取一个字节用于判断当前分段是否为最后一段
+func (dr *decryptReader) readChunk() error {
+	start := 0
+	if dr.hasPending {
+		dr.buf[0] = dr.pending
+		start = 1
+	}
+	n, err := io.ReadFull(dr.r, dr.buf[start:])
+	n += start
+	last := false
+	switch err {
+	case nil:
+		dr.pending, dr.hasPending = dr.buf[n-1], true
+		n--
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	if n < dr.aead.Overhead() {
+		return ErrInvalidCiphertext
+	}
+	nonce, err := dr.next(last)
+	if err != nil {
+		return err
+	}
+	dr.plain, err = dr.aead.Open(dr.buf[:0], nonce, dr.buf[:n], dr.header)
+	if err != nil {
+		return ErrAuthFailed
+	}
+	dr.done = last
+	return nil
+}
@@ -0,0 +1,113 @@
+package xutils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptWriter(t *testing.T) {
+	key := []byte("secret key")
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 100} {
+		text := make([]byte, size)
+		rand.Read(text)
+
+		var buf bytes.Buffer
+		w, err := NewEncryptWriter(&buf, key)
+		assert.Nil(t, err)
+		// 分多次写入
+		for p := text; len(p) > 0; {
+			n := 1000
+			if n > len(p) {
+				n = len(p)
+			}
+			_, err = w.Write(p[:n])
+			assert.Nil(t, err)
+			p = p[n:]
+		}
+		assert.Nil(t, w.Close())
+		cipherText := buf.Bytes()
+
+		r, err := NewDecryptReader(bytes.NewReader(cipherText), key)
+		assert.Nil(t, err)
+		text2, err := io.ReadAll(r)
+		assert.Nil(t, err)
+		assert.True(t, bytes.Equal(text, text2), "size %d", size)
+
+		// 错误的密钥
+		r, err = NewDecryptReader(bytes.NewReader(cipherText), []byte("wrong key"))
+		assert.Nil(t, err)
+		_, err = io.ReadAll(r)
+		assert.NotNil(t, err)
+
+		// 截断或追加数据
+		for _, c := range [][]byte{
+			cipherText[:len(cipherText)-1],
+			cipherText[:streamHeaderSize],
+			append(append([]byte{}, cipherText...), 0),
+		} {
+			r, err = NewDecryptReader(bytes.NewReader(c), key)
+			if err == nil {
+				_, err = io.ReadAll(r)
+			}
+			assert.NotNil(t, err, "size %d", size)
+		}
+	}
+}
+
+func TestEncryptWriterTruncateAtChunk(t *testing.T) {
+	key := []byte("secret key")
+	text := make([]byte, 2*streamChunkSize+10)
+	var buf bytes.Buffer
+	w, _ := NewEncryptWriter(&buf, key)
+	w.Write(text)
+	w.Close()
+
+	// 在分段边界处截断，缺少结束标记
+	c := buf.Bytes()[:streamHeaderSize+streamChunkSize+16]
+	r, err := NewDecryptReader(bytes.NewReader(c), key)
+	assert.Nil(t, err)
+	_, err = io.ReadAll(r)
+	assert.NotNil(t, err)
+}
+
+func TestEncryptFile(t *testing.T) {
+	dir, clean := TempDir("cipher")
+	defer clean()
+	src := filepath.Join(dir, "src.txt")
+	enc := filepath.Join(dir, "src.txt.enc")
+	dst := filepath.Join(dir, "dst.txt")
+	text := bytes.Repeat([]byte("hello world\n"), 20000)
+	assert.Nil(t, os.WriteFile(src, text, PrivateFileMode))
+
+	key := []byte("secret key")
+	assert.Nil(t, EncryptFile(src, enc, key))
+	assert.Nil(t, DecryptFile(enc, dst, key))
+	text2, err := os.ReadFile(dst)
+	assert.Nil(t, err)
+	assert.Equal(t, text, text2)
+
+	// 解密后的文件只有所有者可以读写
+	info, err := os.Stat(dst)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(OwnerOnlyFileMode), info.Mode().Perm())
+
+	// 解密失败时不生成目标文件
+	dst2 := filepath.Join(dir, "dst2.txt")
+	assert.NotNil(t, DecryptFile(enc, dst2, []byte("wrong key")))
+	assert.False(t, IsFile(dst2))
+	names, _ := ReadDir(dir)
+	assert.Equal(t, []string{"dst.txt", "src.txt", "src.txt.enc"}, names)
+}
+
+func TestPkcs5Padding(t *testing.T) {
+	buf := []byte("hello world......")
+	text := buf[:5]
+	padded := pkcs5Padding(text, 16)
+	assert.Equal(t, 16, len(padded))
+	assert.Equal(t, "hello world......", string(buf))
+}
@@ -1,7 +1,12 @@
 package xutils
 
 import (
+	"bytes"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"io"
 	"testing"
+	"time"
 )
 
 func TestAesEncrypt(t *testing.T) {
@@ -22,3 +27,228 @@ func TestAesEncrypt(t *testing.T) {
 		t.Errorf("AES加密解密测试失败: %v != %v", string(text2), string(text))
 	}
 }
+
+func TestAesGCMEncrypt(t *testing.T) {
+	text := []byte("hello world")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	cipherText, err := AesGCMEncrypt(text, key, []byte("user:1"))
+	assert.Nil(t, err)
+	assert.Equal(t, len(text)+12+16, len(cipherText))
+
+	text2, err := AesGCMDecrypt(cipherText, key, []byte("user:1"))
+	assert.Nil(t, err)
+	assert.Equal(t, text, text2)
+
+	// 关联数据不一致时解密失败
+	_, err = AesGCMDecrypt(cipherText, key, []byte("user:2"))
+	assert.NotNil(t, err)
+	_, err = AesGCMDecrypt(cipherText, key)
+	assert.NotNil(t, err)
+
+	// 不带关联数据
+	cipherText, err = AesGCMEncrypt(text, key)
+	assert.Nil(t, err)
+	text2, err = AesGCMDecrypt(cipherText, key)
+	assert.Nil(t, err)
+	assert.Equal(t, text, text2)
+
+	// 最多只能传入一个关联数据
+	_, err = AesGCMEncrypt(text, key, []byte("user:1"), []byte("record:1"))
+	assert.NotNil(t, err)
+	_, err = AesGCMDecrypt(cipherText, key, nil, []byte("record:1"))
+	assert.NotNil(t, err)
+
+	// 密钥长度错误
+	_, err = AesGCMEncrypt(text, []byte("short"))
+	assert.NotNil(t, err)
+	_, err = AesGCMDecrypt(cipherText[:10], key)
+	assert.NotNil(t, err)
+}
+
+func TestAEAD(t *testing.T) {
+	a, err := NewAEAD(make([]byte, 16))
+	assert.Nil(t, err)
+	c1, err := a.Seal([]byte("data"), nil)
+	assert.Nil(t, err)
+	c2, err := a.Seal([]byte("data"), nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, c1, c2)
+	assert.Equal(t, 4+a.Overhead(), len(c1))
+
+	c1[len(c1)-1] ^= 1
+	_, err = a.Open(c1, nil)
+	assert.NotNil(t, err)
+
+	p, err := a.Open(c2, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("data"), p)
+}
+
+func TestAesEncryptWithKDF(t *testing.T) {
+	text := []byte("hello world")
+	key := []byte("secret key")
+	for _, kdf := range []KDF{HKDF, PBKDF2, Scrypt} {
+		cipherText, err := AesEncryptWithKDF(text, key, kdf)
+		assert.Nil(t, err)
+		assert.Equal(t, byte(0xA5), cipherText[0])
+		assert.Equal(t, byte(kdf), cipherText[2])
+		assert.NotEqual(t, 0, len(cipherText)%16)
+
+		text2, err := AesDecrypt(cipherText, key)
+		assert.Nil(t, err)
+		assert.Equal(t, text, text2)
+
+		_, err = AesDecrypt(cipherText, []byte("wrong key"))
+		assert.NotNil(t, err)
+
+		// 篡改头部
+		cipherText[5] ^= 1
+		_, err = AesDecrypt(cipherText, key)
+		assert.NotNil(t, err)
+	}
+	_, err := AesEncryptWithKDF(text, key, KDF(99))
+	assert.NotNil(t, err)
+}
+
+func TestAesDecryptLegacy(t *testing.T) {
+	// 旧版本 AesEncrypt 生成的密文
+	cipherText, _ := hex.DecodeString("bd7de1e5958255a8233fe71dc1cc9e5993458ac08ccf2afd023395265bcd72d9" +
+		"5904a23719cc34c83775055b640f8f977bae5d7878a25eed8ff99ac7941336b7")
+	text, err := AesDecrypt(cipherText, []byte("secret key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", string(text))
+
+	_, err = AesDecrypt(cipherText, []byte("wrong key"))
+	assert.NotNil(t, err)
+}
+
+func TestCipherErrors(t *testing.T) {
+	key := []byte("secret key")
+	cipherText, _ := AesEncrypt([]byte("hello world"), key)
+
+	_, err := AesDecrypt(cipherText, []byte("wrong key"))
+	assert.ErrorIs(t, err, ErrAuthFailed)
+	_, err = AesDecrypt(cipherText[:20], key)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = AesDecrypt(nil, key)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = AesDecrypt(make([]byte, 17), key)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	// 旧格式密文长度不正确
+	_, err = AesDecrypt(make([]byte, 32), key)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = AesDecrypt(make([]byte, 64), key)
+	assert.ErrorIs(t, err, ErrAuthFailed)
+
+	bad := append([]byte{}, cipherText...)
+	bad[1] = 9
+	_, err = AesDecrypt(bad, key)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	gcmKey := make([]byte, 32)
+	gcmText, _ := AesGCMEncrypt([]byte("hello"), gcmKey)
+	gcmText[0] ^= 1
+	_, err = AesGCMDecrypt(gcmText, gcmKey)
+	assert.ErrorIs(t, err, ErrAuthFailed)
+}
+
+func TestAesDecryptKDFLimit(t *testing.T) {
+	key := []byte("secret key")
+	tests := [][]byte{
+		// scrypt logN=20, r=2, p=16
+		append([]byte{0xA5, 1, byte(Scrypt), 20, 2, 16}, make([]byte, 80)...),
+		append([]byte{0xA5, 1, byte(Scrypt), 15, 8, 2}, make([]byte, 80)...),
+		append([]byte{0xA5, 1, byte(Scrypt), 0, 8, 1}, make([]byte, 80)...),
+		// pbkdf2 4194304 次迭代
+		append([]byte{0xA5, 1, byte(PBKDF2), 0x00, 0x40, 0x00, 0x00}, make([]byte, 80)...),
+		append([]byte{0xA5, 1, byte(PBKDF2), 0, 0, 0, 0}, make([]byte, 80)...),
+	}
+	for _, cipherText := range tests {
+		start := time.Now()
+		_, err := AesDecrypt(cipherText, key)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+		assert.Less(t, time.Since(start), time.Second)
+	}
+
+	// Keyring 对无头部的密文会逐个尝试所有密钥
+	kr := NewKeyring()
+	for _, id := range []string{"k1", "k2", "k3"} {
+		assert.Nil(t, kr.Add(id, []byte(id)))
+	}
+	start := time.Now()
+	_, err := kr.Decrypt(tests[0])
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 使用默认参数加密的密文可以正常解密
+	for _, kdf := range []KDF{PBKDF2, Scrypt} {
+		cipherText, err := AesEncryptWithKDF([]byte("hello world"), key, kdf)
+		assert.Nil(t, err)
+		text, err := AesDecrypt(cipherText, key)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello world", string(text))
+	}
+
+	// 调低限制后拒绝默认参数的密文
+	old := MaxScryptLogN
+	MaxScryptLogN = 14
+	defer func() { MaxScryptLogN = old }()
+	cipherText, _ := AesEncryptWithKDF([]byte("hello world"), key, Scrypt)
+	_, err = AesDecrypt(cipherText, key)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestPkcs5UnPadding(t *testing.T) {
+	tests := []struct {
+		text []byte
+		want []byte
+		err  error
+	}{
+		{append([]byte("hello world"), 5, 5, 5, 5, 5), []byte("hello world"), nil},
+		{bytes.Repeat([]byte{16}, 16), []byte{}, nil},
+		{append([]byte("hello world"), 5, 5, 5, 4, 5), nil, ErrInvalidPadding},
+		{append([]byte("hello world!!!!"), 0), nil, ErrInvalidPadding},
+		{append([]byte("hello world!!!!"), 17), nil, ErrInvalidPadding},
+		{append([]byte("hello world!!!!"), 255), nil, ErrInvalidPadding},
+		{[]byte{1}, nil, ErrInvalidPadding},
+		{nil, nil, ErrInvalidPadding},
+	}
+	for _, tt := range tests {
+		got, err := pkcs5UnPadding(tt.text, 16)
+		assert.Equal(t, tt.err, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func FuzzAesDecrypt(f *testing.F) {
+	key := []byte("secret key")
+	for _, kdf := range []KDF{HKDF, Scrypt} {
+		c, _ := AesEncryptWithKDF([]byte("hello world"), key, kdf)
+		f.Add(c)
+	}
+	legacy, _ := hex.DecodeString("bd7de1e5958255a8233fe71dc1cc9e5993458ac08ccf2afd023395265bcd72d9" +
+		"5904a23719cc34c83775055b640f8f977bae5d7878a25eed8ff99ac7941336b7")
+	f.Add(legacy)
+	f.Add([]byte{})
+	f.Add([]byte{0xA5, 1, 3, 20, 255, 255})
+	// 超出 KDF 参数限制的头部
+	f.Add(append([]byte{0xA5, 1, byte(Scrypt), 20, 2, 16}, make([]byte, 80)...))
+	f.Add(append([]byte{0xA5, 1, byte(PBKDF2), 0xff, 0xff, 0xff, 0xff}, make([]byte, 80)...))
+	f.Fuzz(func(t *testing.T, cipherText []byte) {
+		start := time.Now()
+		text, err := AesDecrypt(cipherText, key)
+		if err == nil && string(text) != "hello world" {
+			t.Fatalf("unexpected plain text %q", text)
+		}
+		// 密钥派生的开销不能超过默认参数
+		if d := time.Since(start); d > 5*time.Second {
+			t.Fatalf("decrypt took %v", d)
+		}
+		_, _ = AesGCMDecrypt(cipherText, make([]byte, 32))
+		if r, err := NewDecryptReader(bytes.NewReader(cipherText), key); err == nil {
+			_, _ = io.ReadAll(r)
+		}
+	})
+}
@@ -16,6 +16,8 @@ import (
 const (
 	PrivateFileMode = 0644
 	PrivateDirMode  = 0755
+	// OwnerOnlyFileMode 只有所有者可以读写，用于解密后的文件等敏感数据
+	OwnerOnlyFileMode = 0600
 )
 
 var (
@@ -315,3 +317,36 @@ func MoveUploadFile(upfile *multipart.FileHeader, toPath string) (string, error)
 	}
 	return toPath, nil
 }
+
+// writeFileAtomic 先写入同目录下的临时文件，成功后再重命名为 filename，
+// 避免写入过程中出错或进程退出时留下不完整的文件，文件权限为 PrivateFileMode
+func writeFileAtomic(filename string, fn func(w io.Writer) error) error {
+	return writeFileAtomicMode(filename, PrivateFileMode, fn)
+}
+
+// writeFileAtomicMode 与 writeFileAtomic 相同，perm 为最终文件的权限
+func writeFileAtomicMode(filename string, perm os.FileMode, fn func(w io.Writer) error) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err = fn(f); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
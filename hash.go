@@ -3,16 +3,26 @@ package xutils
 import (
 	"bytes"
 	"crypto"
+	"crypto/hmac"
 	_ "crypto/md5"
 	_ "crypto/sha1"
 	_ "crypto/sha256"
 	_ "crypto/sha512"
 	"encoding/hex"
 	"errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
+	"hash"
+	"hash/adler32"
 	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 type HashAlgo uint
@@ -26,17 +36,203 @@ const (
 	SHA512
 	SHA512_224
 	SHA512_256
+	SHA3_224
+	SHA3_256
+	SHA3_384
+	SHA3_512
+	SHAKE128 // 输出32字节
+	SHAKE256 // 输出64字节
+	BLAKE2B_256
+	BLAKE2B_384
+	BLAKE2B_512
+	BLAKE2S_256
+	CRC32_IEEE
+	CRC32_CASTAGNOLI
+	CRC32_KOOPMAN
+	CRC64_ISO
+	CRC64_ECMA
+	ADLER32
+	FNV1_32
+	FNV1A_32
+	FNV1_64
+	FNV1A_64
+	FNV1_128
+	FNV1A_128
+	XXH64
+	XXH3_64
+	MURMUR3_32
+	MURMUR3_128
 )
 
-var hashes = map[HashAlgo]crypto.Hash{
-	MD5:        crypto.MD5,
-	SHA1:       crypto.SHA1,
-	SHA224:     crypto.SHA224,
-	SHA256:     crypto.SHA256,
-	SHA384:     crypto.SHA384,
-	SHA512:     crypto.SHA512,
-	SHA512_224: crypto.SHA512_224,
-	SHA512_256: crypto.SHA512_256,
+var (
+	crc32CastagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+	crc32KoopmanTable    = crc32.MakeTable(crc32.Koopman)
+	crc64ISOTable        = crc64.MakeTable(crc64.ISO)
+	crc64ECMATable       = crc64.MakeTable(crc64.ECMA)
+)
+
+var hashes = map[HashAlgo]func() hash.Hash{
+	MD5:              crypto.MD5.New,
+	SHA1:             crypto.SHA1.New,
+	SHA224:           crypto.SHA224.New,
+	SHA256:           crypto.SHA256.New,
+	SHA384:           crypto.SHA384.New,
+	SHA512:           crypto.SHA512.New,
+	SHA512_224:       crypto.SHA512_224.New,
+	SHA512_256:       crypto.SHA512_256.New,
+	SHA3_224:         sha3.New224,
+	SHA3_256:         sha3.New256,
+	SHA3_384:         sha3.New384,
+	SHA3_512:         sha3.New512,
+	SHAKE128:         func() hash.Hash { return &shakeHash{sha3.NewShake128(), 32} },
+	SHAKE256:         func() hash.Hash { return &shakeHash{sha3.NewShake256(), 64} },
+	BLAKE2B_256:      func() hash.Hash { h, _ := blake2b.New256(nil); return h },
+	BLAKE2B_384:      func() hash.Hash { h, _ := blake2b.New384(nil); return h },
+	BLAKE2B_512:      func() hash.Hash { h, _ := blake2b.New512(nil); return h },
+	BLAKE2S_256:      func() hash.Hash { h, _ := blake2s.New256(nil); return h },
+	CRC32_IEEE:       func() hash.Hash { return crc32.NewIEEE() },
+	CRC32_CASTAGNOLI: func() hash.Hash { return crc32.New(crc32CastagnoliTable) },
+	CRC32_KOOPMAN:    func() hash.Hash { return crc32.New(crc32KoopmanTable) },
+	CRC64_ISO:        func() hash.Hash { return crc64.New(crc64ISOTable) },
+	CRC64_ECMA:       func() hash.Hash { return crc64.New(crc64ECMATable) },
+	ADLER32:          func() hash.Hash { return adler32.New() },
+	FNV1_32:          func() hash.Hash { return fnv.New32() },
+	FNV1A_32:         func() hash.Hash { return fnv.New32a() },
+	FNV1_64:          func() hash.Hash { return fnv.New64() },
+	FNV1A_64:         func() hash.Hash { return fnv.New64a() },
+	FNV1_128:         fnv.New128,
+	FNV1A_128:        fnv.New128a,
+	XXH64:            func() hash.Hash { return NewXXHash64(0) },
+	XXH3_64:          func() hash.Hash { return NewXXH3Hash64(0) },
+	MURMUR3_32:       func() hash.Hash { return NewMurmur3Hash32(0) },
+	MURMUR3_128:      func() hash.Hash { return NewMurmur3Hash128(0) },
+}
+
+// cryptoHashes 密码学安全的哈希算法，只有这些算法可以用于 HMAC 等需要密钥的场景
+// CRC、Adler、FNV、xxHash、MurmurHash 等非密码学算法容易构造碰撞，不能用于签名
+var cryptoHashes = map[HashAlgo]bool{
+	MD5:         true,
+	SHA1:        true,
+	SHA224:      true,
+	SHA256:      true,
+	SHA384:      true,
+	SHA512:      true,
+	SHA512_224:  true,
+	SHA512_256:  true,
+	SHA3_224:    true,
+	SHA3_256:    true,
+	SHA3_384:    true,
+	SHA3_512:    true,
+	SHAKE128:    true,
+	SHAKE256:    true,
+	BLAKE2B_256: true,
+	BLAKE2B_384: true,
+	BLAKE2B_512: true,
+	BLAKE2S_256: true,
+}
+
+// hashNames 算法名称，第一个为 String 返回的名称，其余为 ParseHashAlgo 支持的别名
+var hashNames = map[HashAlgo][]string{
+	MD5:              {"md5"},
+	SHA1:             {"sha1"},
+	SHA224:           {"sha224"},
+	SHA256:           {"sha256"},
+	SHA384:           {"sha384"},
+	SHA512:           {"sha512"},
+	SHA512_224:       {"sha512-224"},
+	SHA512_256:       {"sha512-256"},
+	SHA3_224:         {"sha3-224"},
+	SHA3_256:         {"sha3-256"},
+	SHA3_384:         {"sha3-384"},
+	SHA3_512:         {"sha3-512"},
+	SHAKE128:         {"shake128"},
+	SHAKE256:         {"shake256"},
+	BLAKE2B_256:      {"blake2b-256"},
+	BLAKE2B_384:      {"blake2b-384"},
+	BLAKE2B_512:      {"blake2b-512", "blake2b", "b2"},
+	BLAKE2S_256:      {"blake2s-256", "blake2s"},
+	CRC32_IEEE:       {"crc32", "crc32-ieee"},
+	CRC32_CASTAGNOLI: {"crc32c", "crc32-castagnoli"},
+	CRC32_KOOPMAN:    {"crc32-koopman", "crc32k"},
+	CRC64_ISO:        {"crc64-iso"},
+	CRC64_ECMA:       {"crc64-ecma"},
+	ADLER32:          {"adler32"},
+	FNV1_32:          {"fnv1-32", "fnv32"},
+	FNV1A_32:         {"fnv1a-32", "fnv32a"},
+	FNV1_64:          {"fnv1-64", "fnv64"},
+	FNV1A_64:         {"fnv1a-64", "fnv64a"},
+	FNV1_128:         {"fnv1-128", "fnv128"},
+	FNV1A_128:        {"fnv1a-128", "fnv128a"},
+	XXH64:            {"xxh64", "xxhash64"},
+	XXH3_64:          {"xxh3", "xxh3-64"},
+	MURMUR3_32:       {"murmur3-32", "murmur3a"},
+	MURMUR3_128:      {"murmur3-128", "murmur3f"},
+}
+
+// String 返回算法名称，如 sha3-256
+func (algo HashAlgo) String() string {
+	if names, ok := hashNames[algo]; ok {
+		return names[0]
+	}
+	return "HashAlgo(" + strconv.Itoa(int(algo)) + ")"
+}
+
+// ParseHashAlgo 根据名称返回哈希算法，不区分大小写，忽略 "-"、"_"、"/" 等分隔符，
+// 如 "SHA-256"、"sha3_256"、"SHA512/256"、"blake2b"
+func ParseHashAlgo(name string) (HashAlgo, error) {
+	key := normalizeHashName(name)
+	for algo, names := range hashNames {
+		for _, n := range names {
+			if normalizeHashName(n) == key {
+				return algo, nil
+			}
+		}
+	}
+	return 0, errors.New("unknown hash function: " + name)
+}
+
+// MarshalText 实现 encoding.TextMarshaler，在JSON等格式中以算法名称表示
+func (algo HashAlgo) MarshalText() ([]byte, error) {
+	if _, ok := hashNames[algo]; !ok {
+		return nil, errors.New("unknown hash function")
+	}
+	return []byte(algo.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (algo *HashAlgo) UnmarshalText(text []byte) error {
+	a, err := ParseHashAlgo(string(text))
+	if err != nil {
+		return err
+	}
+	*algo = a
+	return nil
+}
+
+func normalizeHashName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '/', ' ':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// shakeHash 将 SHAKE 可变长度输出的算法包装为固定长度输出的 hash.Hash
+type shakeHash struct {
+	sha3.ShakeHash
+	size int
+}
+
+func (h *shakeHash) Size() int {
+	return h.size
+}
+
+func (h *shakeHash) Sum(b []byte) []byte {
+	out := make([]byte, h.size)
+	h.Clone().Read(out)
+	return append(b, out...)
 }
 
 // Hash 计算哈希值，输入为byte slice
@@ -62,22 +258,83 @@ func HashString(algo HashAlgo, data string, rawOutput bool) (string, error) {
 
 // HashReader 计算哈希值，输入为io.Reader
 func HashReader(algo HashAlgo, rd io.Reader, rawOutput bool) ([]byte, error) {
+	return HashReaderEncoded(algo, rd, boolEncoding(rawOutput))
+}
+
+// Hmac 计算HMAC，输入为byte slice
+func Hmac(algo HashAlgo, key []byte, data []byte, rawOutput bool) ([]byte, error) {
+	return HmacReader(algo, key, bytes.NewReader(data), rawOutput)
+}
+
+// HmacFile 计算文件的HMAC
+func HmacFile(algo HashAlgo, key []byte, filename string, rawOutput bool) ([]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return HmacReader(algo, key, f, rawOutput)
+}
+
+// HmacString 计算HMAC，输入为字符串
+func HmacString(algo HashAlgo, key string, data string, rawOutput bool) (string, error) {
+	res, err := HmacReader(algo, []byte(key), strings.NewReader(data), rawOutput)
+	return string(res), err
+}
+
+// HmacReader 计算HMAC，输入为io.Reader，algo 必须是密码学哈希算法，CRC、FNV 等算法会返回错误
+func HmacReader(algo HashAlgo, key []byte, rd io.Reader, rawOutput bool) ([]byte, error) {
+	return HmacReaderEncoded(algo, key, rd, boolEncoding(rawOutput))
+}
+
+// HmacVerify 校验HMAC签名，使用恒定时间比较
+// mac 可以是原始字节，也可以是十六进制字符串（不区分大小写），十六进制字符串可以带有 "算法名=" 前缀，
+// 如 GitHub Webhook 签名头 X-Hub-Signature-256 的值 "sha256=<hex>"
+func HmacVerify(algo HashAlgo, key []byte, data []byte, mac []byte) bool {
+	expected, err := Hmac(algo, key, data, true)
+	if err != nil {
+		return false
+	}
+	prefix := algo.String() + "="
+	if len(mac) == len(prefix)+hex.EncodedLen(len(expected)) && strings.EqualFold(string(mac[:len(prefix)]), prefix) {
+		mac = mac[len(prefix):]
+	}
+	if len(mac) == hex.EncodedLen(len(expected)) {
+		decoded := make([]byte, len(expected))
+		if _, err = hex.Decode(decoded, mac); err != nil {
+			return false
+		}
+		mac = decoded
+	}
+	return hmac.Equal(expected, mac)
+}
+
+func newHash(algo HashAlgo) (hash.Hash, error) {
 	f, ok := hashes[algo]
 	if !ok {
 		return nil, errors.New("unknown hash function")
 	}
-	hash := f.New()
-	_, err := io.Copy(hash, rd)
+	return f(), nil
+}
+
+// hmacHash 返回用于 HMAC 的哈希函数，非密码学算法返回错误
+func hmacHash(algo HashAlgo) (func() hash.Hash, error) {
+	f, ok := hashes[algo]
+	if !ok {
+		return nil, errors.New("unknown hash function")
+	}
+	if !cryptoHashes[algo] {
+		return nil, errors.New("hash function is not cryptographic: " + algo.String())
+	}
+	return f, nil
+}
+
+func sumReader(h hash.Hash, rd io.Reader, enc HashEncoding) ([]byte, error) {
+	_, err := io.Copy(h, rd)
 	if err != nil {
 		return nil, err
 	}
-	result := hash.Sum(nil)
-	if rawOutput {
-		return result, nil
-	}
-	dst := make([]byte, hex.EncodedLen(len(result)))
-	hex.Encode(dst, result)
-	return dst, nil
+	return EncodeHash(h.Sum(nil), enc)
 }
 
 // CRC32 计算CRC32
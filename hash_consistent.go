@@ -0,0 +1,94 @@
+package xutils
+
+import (
+	"math"
+)
+
+// Rendezvous 使用加权的 Rendezvous（HRW，最高随机权重）哈希从 nodes 中为 key 选择一个节点
+// weights 与 nodes 一一对应，为 nil 时所有节点权重相同；权重小于等于0的节点不会被选中
+// 增删节点时只有被选中节点变化的 key 会迁移，适合节点数较少、不希望维护哈希环的场景
+// nodes 为空时返回空字符串
+func Rendezvous(key string, nodes []string, weights []float64) string {
+	best := -1
+	bestScore := math.Inf(-1)
+	for i, node := range nodes {
+		score := rendezvousScore(key, node, weights, i)
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return nodes[best]
+}
+
+// RendezvousN 按 Rendezvous 的得分从高到低返回 n 个节点，第一个与 Rendezvous 的结果相同，可用于多副本存储
+func RendezvousN(key string, nodes []string, weights []float64, n int) []string {
+	type scored struct {
+		node  string
+		score float64
+	}
+	list := make([]scored, 0, len(nodes))
+	for i, node := range nodes {
+		score := rendezvousScore(key, node, weights, i)
+		if !math.IsInf(score, -1) {
+			list = append(list, scored{node, score})
+		}
+	}
+	if n > len(list) {
+		n = len(list)
+	}
+	res := make([]string, 0, n)
+	// n 通常很小，使用选择排序即可
+	for len(res) < n {
+		k := len(res)
+		for i := k + 1; i < len(list); i++ {
+			if list[i].score > list[k].score {
+				list[i], list[k] = list[k], list[i]
+			}
+		}
+		res = append(res, list[k].node)
+	}
+	return res
+}
+
+// rendezvousScore 计算节点得分：score = w / -ln(u)，u 为 (node, key) 的哈希值映射到 (0, 1) 区间
+// 权重相同时等价于比较哈希值的大小
+func rendezvousScore(key, node string, weights []float64, i int) float64 {
+	w := 1.0
+	if weights != nil {
+		if i >= len(weights) || weights[i] <= 0 {
+			return math.Inf(-1)
+		}
+		w = weights[i]
+	}
+	buf := make([]byte, 0, len(node)+1+len(key))
+	buf = append(buf, node...)
+	buf = append(buf, 0)
+	buf = append(buf, key...)
+	h := XXH3Hash64(buf, 0)
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return w / -math.Log(u)
+}
+
+// JumpHash Google 的 Jump Consistent Hash 算法，将 key 映射到 [0, buckets) 中的一个分片
+// 分片数从 n 增加到 n+1 时，只有约 1/(n+1) 的 key 迁移到新分片；只能在末尾增删分片
+// buckets 小于等于0时返回 -1
+func JumpHash(key uint64, buckets int) int {
+	if buckets <= 0 {
+		return -1
+	}
+	b, j := int64(-1), int64(0)
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// JumpHashString 使用 XXH3Hash64 将字符串 key 转换为整数后计算 JumpHash
+func JumpHashString(key string, buckets int) int {
+	return JumpHash(XXH3Hash64([]byte(key), 0), buckets)
+}
@@ -0,0 +1,100 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestRendezvous(t *testing.T) {
+	assert.Equal(t, "", Rendezvous("key", nil, nil))
+	assert.Equal(t, "a", Rendezvous("key", []string{"a"}, nil))
+	assert.Equal(t, "b", Rendezvous("key", []string{"a", "b"}, []float64{0, 1}))
+
+	// 分布均匀
+	nodes := []string{"n1", "n2", "n3", "n4", "n5"}
+	const keys = 50000
+	counts := make(map[string]int)
+	before := make([]string, keys)
+	for i := range before {
+		before[i] = Rendezvous("key:"+strconv.Itoa(i), nodes, nil)
+		counts[before[i]]++
+	}
+	for _, node := range nodes {
+		assert.InDelta(t, keys/len(nodes), counts[node], float64(keys)/float64(len(nodes))*0.1, node)
+	}
+
+	// 删除节点只影响原属于该节点的 key，添加节点时 key 只会迁移到新节点
+	removed := []string{"n1", "n2", "n4", "n5"}
+	added := append(nodes, "n6")
+	moved := 0
+	for i := range before {
+		key := "key:" + strconv.Itoa(i)
+		if before[i] != "n3" {
+			assert.Equal(t, before[i], Rendezvous(key, removed, nil))
+		}
+		if node := Rendezvous(key, added, nil); node != before[i] {
+			assert.Equal(t, "n6", node)
+			moved++
+		}
+	}
+	assert.InDelta(t, 1.0/6, float64(moved)/keys, 0.02)
+}
+
+func TestRendezvousWeighted(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+	weights := []float64{1, 2, 3}
+	const keys = 60000
+	counts := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		counts[Rendezvous(strconv.Itoa(i), nodes, weights)]++
+	}
+	for i, node := range nodes {
+		want := keys * weights[i] / 6
+		assert.InDelta(t, want, counts[node], want*0.1, node)
+	}
+}
+
+func TestRendezvousN(t *testing.T) {
+	nodes := []string{"n1", "n2", "n3", "n4", "n5"}
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		res := RendezvousN(key, nodes, nil, 3)
+		assert.Equal(t, 3, len(res))
+		assert.Equal(t, Rendezvous(key, nodes, nil), res[0])
+		assert.Equal(t, 3, len(ArrayUnique(res)))
+	}
+	assert.Equal(t, 2, len(RendezvousN("key", nodes, []float64{1, 0, 1, 0, 0}, 3)))
+	assert.Equal(t, 0, len(RendezvousN("key", nil, nil, 3)))
+}
+
+func TestJumpHash(t *testing.T) {
+	// 与论文参考实现的结果一致
+	assert.Equal(t, 0, JumpHash(1, 1))
+	assert.Equal(t, 43, JumpHash(42, 57))
+	assert.Equal(t, 0, JumpHash(0xDEAD10CC, 1))
+	assert.Equal(t, 361, JumpHash(0xDEAD10CC, 666))
+	assert.Equal(t, 520, JumpHash(256, 1024))
+	assert.Equal(t, -1, JumpHash(1, 0))
+
+	// 分布均匀
+	const buckets, keys = 10, 100000
+	counts := make([]int, buckets)
+	for i := 0; i < keys; i++ {
+		counts[JumpHashString("key:"+strconv.Itoa(i), buckets)]++
+	}
+	for b, c := range counts {
+		assert.InDelta(t, keys/buckets, c, float64(keys)/buckets*0.05, "bucket %d", b)
+	}
+
+	// 分片数增加时，key 要么不变，要么迁移到新分片
+	moved := 0
+	for i := uint64(0); i < keys; i++ {
+		a, b := JumpHash(i*0x9E3779B97F4A7C15, buckets), JumpHash(i*0x9E3779B97F4A7C15, buckets+1)
+		if a != b {
+			assert.Equal(t, buckets, b)
+			moved++
+		}
+	}
+	assert.InDelta(t, 1.0/(buckets+1), float64(moved)/keys, 0.01)
+}
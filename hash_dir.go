@@ -0,0 +1,221 @@
+package xutils
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// HashDirOptions HashDir 的可选参数
+type HashDirOptions struct {
+	Manifest   bool // 是否在结果中返回每个文件的清单，VerifyDir 需要用到
+	IgnoreMode bool // 不将文件权限计入哈希，适用于不同机器 umask 不一致的情况
+}
+
+// DirManifest 目录的哈希结果
+type DirManifest struct {
+	Algo       HashAlgo        `json:"algo"`
+	IgnoreMode bool            `json:"ignore_mode,omitempty"`
+	Root       []byte          `json:"root"`
+	Files      []ManifestEntry `json:"files,omitempty"`
+}
+
+// ManifestEntry 清单中的单个文件
+// Path 为相对于根目录、以 "/" 分隔的路径，符号链接的 Sum 为链接目标路径的哈希值
+type ManifestEntry struct {
+	Path string      `json:"path"`
+	Mode os.FileMode `json:"mode"`
+	Size int64       `json:"size"`
+	Sum  []byte      `json:"sum"`
+}
+
+// DirDiff VerifyDir 的比较结果，路径均为相对路径
+type DirDiff struct {
+	Missing  []string // 清单中有，目录中不存在
+	Extra    []string // 目录中有，清单中不存在
+	Modified []string // 内容或权限发生了变化
+}
+
+// Equal 目录与清单是否完全一致
+func (d *DirDiff) Equal() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Modified) == 0
+}
+
+// HashDir 计算目录的哈希值
+// 按 ReadDirAll 的方式遍历所有文件（不包含空目录），以排序后的相对路径、文件权限和内容的哈希作为叶子节点构建 Merkle 树，
+// 相同内容的目录在任何机器上得到的 Root 都相同；只计算普通文件和符号链接，命名管道、套接字、设备文件会被忽略
+func HashDir(dir string, algo HashAlgo, opts *HashDirOptions) (*DirManifest, error) {
+	if opts == nil {
+		opts = &HashDirOptions{}
+	}
+	if _, err := newHash(algo); err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New("not a directory: " + dir)
+	}
+	entries := make([]ManifestEntry, 0)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// 打开命名管道会一直阻塞，套接字无法打开
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		entry, err := hashDirEntry(algo, path, info)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entry.Path = filepath.ToSlash(rel)
+		if opts.IgnoreMode {
+			entry.Mode &^= os.ModePerm
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	m := &DirManifest{Algo: algo, IgnoreMode: opts.IgnoreMode}
+	m.Root = merkleRoot(algo, entries)
+	if opts.Manifest {
+		m.Files = entries
+	}
+	return m, nil
+}
+
+// VerifyDir 将目录与清单进行比较，返回缺失、多出和被修改的文件
+// 清单需要使用 HashDirOptions.Manifest 生成，否则只能比较 Root，不一致时返回错误
+func VerifyDir(dir string, manifest *DirManifest) (*DirDiff, error) {
+	if manifest == nil {
+		return nil, errors.New("manifest is nil")
+	}
+	current, err := HashDir(dir, manifest.Algo, &HashDirOptions{Manifest: true, IgnoreMode: manifest.IgnoreMode})
+	if err != nil {
+		return nil, err
+	}
+	diff := &DirDiff{}
+	if bytes.Equal(current.Root, manifest.Root) {
+		return diff, nil
+	}
+	// 没有文件清单时无法确定差异，除非清单对应的就是空目录
+	if len(manifest.Files) == 0 && !bytes.Equal(manifest.Root, merkleRoot(manifest.Algo, nil)) {
+		return nil, errors.New("manifest does not contain file entries")
+	}
+	expected := make(map[string]ManifestEntry, len(manifest.Files))
+	for _, e := range manifest.Files {
+		expected[e.Path] = e
+	}
+	for _, e := range current.Files {
+		old, ok := expected[e.Path]
+		if !ok {
+			diff.Extra = append(diff.Extra, e.Path)
+			continue
+		}
+		delete(expected, e.Path)
+		if old.Mode != e.Mode || !bytes.Equal(old.Sum, e.Sum) {
+			diff.Modified = append(diff.Modified, e.Path)
+		}
+	}
+	for path := range expected {
+		diff.Missing = append(diff.Missing, path)
+	}
+	sort.Strings(diff.Missing)
+	// Root 不一致但文件清单没有差异，说明清单被修改过
+	if diff.Equal() {
+		return nil, errors.New("manifest root does not match file entries")
+	}
+	return diff, nil
+}
+
+func hashDirEntry(algo HashAlgo, path string, info os.FileInfo) (ManifestEntry, error) {
+	entry := ManifestEntry{
+		Mode: info.Mode() & (os.ModeType | os.ModePerm),
+		Size: info.Size(),
+	}
+	h, _ := newHash(algo)
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return entry, err
+		}
+		h.Write([]byte(filepath.ToSlash(target)))
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return entry, err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return entry, err
+		}
+	}
+	entry.Sum = h.Sum(nil)
+	return entry, nil
+}
+
+// merkleRoot 计算 Merkle 树的根
+// 叶子节点为 H(0x00 || len(path) || path || mode || sum)，中间节点为 H(0x01 || left || right)，
+// 奇数个节点时最后一个直接提升到上一层
+func merkleRoot(algo HashAlgo, entries []ManifestEntry) []byte {
+	h, _ := newHash(algo)
+	if len(entries) == 0 {
+		return h.Sum(nil)
+	}
+	nodes := make([][]byte, len(entries))
+	for i, e := range entries {
+		h.Reset()
+		buf := []byte{0}
+		buf = appendUint32(buf, uint32(len(e.Path)))
+		buf = append(buf, e.Path...)
+		buf = appendUint32(buf, uint32(e.Mode))
+		h.Write(buf)
+		h.Write(e.Sum)
+		nodes[i] = h.Sum(nil)
+	}
+	for len(nodes) > 1 {
+		next := make([][]byte, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				next = append(next, nodes[i])
+				break
+			}
+			next = append(next, merkleNode(h, nodes[i], nodes[i+1]))
+		}
+		nodes = next
+	}
+	return nodes[0]
+}
+
+func merkleNode(h hash.Hash, left, right []byte) []byte {
+	h.Reset()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,159 @@
+package xutils
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func makeHashDirFixture(t *testing.T) string {
+	dir, clean := TempDir("hashdir")
+	t.Cleanup(clean)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a", "b", "c.txt"), []byte("c"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh"), 0755))
+	return dir
+}
+
+func TestHashDir(t *testing.T) {
+	dir1 := makeHashDirFixture(t)
+	dir2 := makeHashDirFixture(t)
+
+	m1, err := HashDir(dir1, SHA256, &HashDirOptions{Manifest: true})
+	assert.Nil(t, err)
+	m2, err := HashDir(dir2, SHA256, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, m1.Root, m2.Root)
+	assert.Equal(t, 32, len(m1.Root))
+	assert.Nil(t, m2.Files)
+
+	// 路径使用 "/" 分隔并按字节序排序
+	paths := make([]string, 0)
+	for _, f := range m1.Files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"a.txt", "a/b/c.txt", "run.sh"}, paths)
+
+	// 内容、文件名、权限的变化都会影响 Root
+	assert.Nil(t, os.WriteFile(filepath.Join(dir2, "a.txt"), []byte("A"), 0644))
+	m2, _ = HashDir(dir2, SHA256, nil)
+	assert.NotEqual(t, m1.Root, m2.Root)
+
+	dir2 = makeHashDirFixture(t)
+	assert.Nil(t, os.Rename(filepath.Join(dir2, "a.txt"), filepath.Join(dir2, "b.txt")))
+	m2, _ = HashDir(dir2, SHA256, nil)
+	assert.NotEqual(t, m1.Root, m2.Root)
+
+	dir2 = makeHashDirFixture(t)
+	assert.Nil(t, os.Chmod(filepath.Join(dir2, "run.sh"), 0644))
+	m2, _ = HashDir(dir2, SHA256, nil)
+	assert.NotEqual(t, m1.Root, m2.Root)
+	m1, _ = HashDir(dir1, SHA256, &HashDirOptions{IgnoreMode: true})
+	m2, _ = HashDir(dir2, SHA256, &HashDirOptions{IgnoreMode: true})
+	assert.Equal(t, m1.Root, m2.Root)
+
+	// 空目录不参与计算
+	assert.Nil(t, os.Mkdir(filepath.Join(dir2, "empty"), 0755))
+	m2, _ = HashDir(dir2, SHA256, &HashDirOptions{IgnoreMode: true})
+	assert.Equal(t, m1.Root, m2.Root)
+
+	_, err = HashDir(dir1, HashAlgo(99), nil)
+	assert.NotNil(t, err)
+	_, err = HashDir(filepath.Join(dir1, "not_exists"), SHA256, nil)
+	assert.NotNil(t, err)
+
+	// 路径为文件时返回错误
+	_, err = HashDir(filepath.Join(dir1, "a.txt"), SHA256, nil)
+	assert.NotNil(t, err)
+	_, err = VerifyDir(filepath.Join(dir1, "a.txt"), m1)
+	assert.NotNil(t, err)
+
+	// 目录路径的写法不影响结果
+	m2, err = HashDir(dir1+string(filepath.Separator)+".", SHA256, &HashDirOptions{IgnoreMode: true})
+	assert.Nil(t, err)
+	assert.Equal(t, m1.Root, m2.Root)
+}
+
+func TestVerifyDir(t *testing.T) {
+	dir := makeHashDirFixture(t)
+	m, err := HashDir(dir, SHA256, &HashDirOptions{Manifest: true})
+	assert.Nil(t, err)
+
+	// 清单可以序列化保存
+	b, err := json.Marshal(m)
+	assert.Nil(t, err)
+	var loaded DirManifest
+	assert.Nil(t, json.Unmarshal(b, &loaded))
+	assert.Equal(t, *m, loaded)
+
+	diff, err := VerifyDir(dir, &loaded)
+	assert.Nil(t, err)
+	assert.True(t, diff.Equal())
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a", "b", "c.txt"), []byte("changed"), 0644))
+	assert.Nil(t, os.Remove(filepath.Join(dir, "a.txt")))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+	assert.Nil(t, os.Chmod(filepath.Join(dir, "run.sh"), 0600))
+	diff, err = VerifyDir(dir, m)
+	assert.Nil(t, err)
+	assert.False(t, diff.Equal())
+	assert.Equal(t, []string{"a.txt"}, diff.Missing)
+	assert.Equal(t, []string{"new.txt"}, diff.Extra)
+	assert.Equal(t, []string{"a/b/c.txt", "run.sh"}, diff.Modified)
+
+	// 没有文件清单时只能比较 Root
+	m, _ = HashDir(dir, SHA256, nil)
+	diff, err = VerifyDir(dir, m)
+	assert.Nil(t, err)
+	assert.True(t, diff.Equal())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new2"), 0644))
+	_, err = VerifyDir(dir, m)
+	assert.NotNil(t, err)
+
+	// 没有文件清单时，删除所有文件也不能视为一致
+	assert.Nil(t, os.RemoveAll(dir))
+	assert.Nil(t, os.Mkdir(dir, 0755))
+	_, err = VerifyDir(dir, m)
+	assert.NotNil(t, err)
+
+	// 清单为空目录时，新增的文件都是多出的
+	empty, _ := HashDir(dir, SHA256, &HashDirOptions{Manifest: true})
+	diff, err = VerifyDir(dir, empty)
+	assert.Nil(t, err)
+	assert.True(t, diff.Equal())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+	diff, err = VerifyDir(dir, empty)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"new.txt"}, diff.Extra)
+}
+
+func TestVerifyDirTamperedRoot(t *testing.T) {
+	dir := makeHashDirFixture(t)
+	m, _ := HashDir(dir, SHA256, &HashDirOptions{Manifest: true})
+	m.Root = append([]byte{}, m.Root...)
+	m.Root[0] ^= 1
+	_, err := VerifyDir(dir, m)
+	assert.NotNil(t, err)
+}
+
+func TestHashDirSpecialFiles(t *testing.T) {
+	dir := makeHashDirFixture(t)
+	m1, _ := HashDir(dir, SHA256, &HashDirOptions{Manifest: true})
+
+	// 套接字等特殊文件不参与计算
+	l, err := net.Listen("unix", filepath.Join(dir, "a", "s.sock"))
+	if err != nil {
+		t.Skip("unix socket not supported:", err)
+	}
+	defer l.Close()
+	m2, err := HashDir(dir, SHA256, &HashDirOptions{Manifest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, m1, m2)
+	diff, err := VerifyDir(dir, m1)
+	assert.Nil(t, err)
+	assert.True(t, diff.Equal())
+}
@@ -0,0 +1,160 @@
+package xutils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// HashEncoding 哈希值的输出编码
+type HashEncoding uint8
+
+const (
+	EncRaw       HashEncoding = iota // 原始字节
+	EncHex                           // 小写十六进制
+	EncHexUpper                      // 大写十六进制
+	EncBase64                        // 标准base64，带填充，如 S3 的 Content-MD5
+	EncBase64URL                     // URL安全的base64，不带填充，适合用作缓存key、文件名
+	EncBase32                        // 标准base32，带填充
+)
+
+var errUnknownHashEncoding = errors.New("unknown hash encoding")
+
+// boolEncoding 将旧接口的 rawOutput 参数转换为对应的编码
+func boolEncoding(rawOutput bool) HashEncoding {
+	if rawOutput {
+		return EncRaw
+	}
+	return EncHex
+}
+
+// EncodeHash 将原始哈希值按指定编码输出
+func EncodeHash(sum []byte, enc HashEncoding) ([]byte, error) {
+	var dst []byte
+	switch enc {
+	case EncRaw:
+		return sum, nil
+	case EncHex, EncHexUpper:
+		dst = make([]byte, hex.EncodedLen(len(sum)))
+		hex.Encode(dst, sum)
+		if enc == EncHexUpper {
+			dst = bytes.ToUpper(dst)
+		}
+	case EncBase64:
+		dst = make([]byte, base64.StdEncoding.EncodedLen(len(sum)))
+		base64.StdEncoding.Encode(dst, sum)
+	case EncBase64URL:
+		dst = make([]byte, base64.RawURLEncoding.EncodedLen(len(sum)))
+		base64.RawURLEncoding.Encode(dst, sum)
+	case EncBase32:
+		dst = make([]byte, base32.StdEncoding.EncodedLen(len(sum)))
+		base32.StdEncoding.Encode(dst, sum)
+	default:
+		return nil, errUnknownHashEncoding
+	}
+	return dst, nil
+}
+
+// DecodeHash 将编码后的哈希值还原为原始字节
+// 十六进制不区分大小写，base64/base32 的填充字符可有可无
+func DecodeHash(digest []byte, enc HashEncoding) ([]byte, error) {
+	var (
+		dst []byte
+		n   int
+		err error
+	)
+	switch enc {
+	case EncRaw:
+		return digest, nil
+	case EncHex, EncHexUpper:
+		dst = make([]byte, hex.DecodedLen(len(digest)))
+		n, err = hex.Decode(dst, digest)
+	case EncBase64, EncBase64URL:
+		digest = bytes.TrimRight(digest, "=")
+		e := base64.RawStdEncoding
+		if enc == EncBase64URL {
+			e = base64.RawURLEncoding
+		}
+		dst = make([]byte, e.DecodedLen(len(digest)))
+		n, err = e.Decode(dst, digest)
+	case EncBase32:
+		digest = bytes.ToUpper(bytes.TrimRight(digest, "="))
+		e := base32.StdEncoding.WithPadding(base32.NoPadding)
+		dst = make([]byte, e.DecodedLen(len(digest)))
+		n, err = e.Decode(dst, digest)
+	default:
+		return nil, errUnknownHashEncoding
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+// HashEqual 使用恒定时间比较原始哈希值 sum 与编码后的哈希值 digest 是否一致
+func HashEqual(sum []byte, digest []byte, enc HashEncoding) bool {
+	decoded, err := DecodeHash(digest, enc)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(sum, decoded)
+}
+
+// HashVerify 计算 data 的哈希值，并与编码后的 digest 进行比较
+func HashVerify(algo HashAlgo, data []byte, digest []byte, enc HashEncoding) bool {
+	sum, err := Hash(algo, data, true)
+	if err != nil {
+		return false
+	}
+	return HashEqual(sum, digest, enc)
+}
+
+// HashEncoded 计算哈希值并按指定编码输出，输入为byte slice
+func HashEncoded(algo HashAlgo, data []byte, enc HashEncoding) ([]byte, error) {
+	return HashReaderEncoded(algo, bytes.NewReader(data), enc)
+}
+
+// HashFileEncoded 计算文件的哈希值并按指定编码输出
+func HashFileEncoded(algo HashAlgo, filename string, enc HashEncoding) ([]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return HashReaderEncoded(algo, f, enc)
+}
+
+// HashStringEncoded 计算哈希值并按指定编码输出，输入为字符串
+func HashStringEncoded(algo HashAlgo, data string, enc HashEncoding) (string, error) {
+	res, err := HashReaderEncoded(algo, strings.NewReader(data), enc)
+	return string(res), err
+}
+
+// HashReaderEncoded 计算哈希值并按指定编码输出，输入为io.Reader
+func HashReaderEncoded(algo HashAlgo, rd io.Reader, enc HashEncoding) ([]byte, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	return sumReader(h, rd, enc)
+}
+
+// HmacEncoded 计算HMAC并按指定编码输出，输入为byte slice
+func HmacEncoded(algo HashAlgo, key []byte, data []byte, enc HashEncoding) ([]byte, error) {
+	return HmacReaderEncoded(algo, key, bytes.NewReader(data), enc)
+}
+
+// HmacReaderEncoded 计算HMAC并按指定编码输出，输入为io.Reader
+func HmacReaderEncoded(algo HashAlgo, key []byte, rd io.Reader, enc HashEncoding) ([]byte, error) {
+	f, err := hmacHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	return sumReader(hmac.New(f, key), rd, enc)
+}
@@ -0,0 +1,95 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestHashEncoded(t *testing.T) {
+	tests := []struct {
+		enc  HashEncoding
+		want string
+	}{
+		{EncHex, "5d41402abc4b2a76b9719d911017c592"},
+		{EncHexUpper, "5D41402ABC4B2A76B9719D911017C592"},
+		{EncBase64, "XUFAKrxLKna5cZ2REBfFkg=="},
+		{EncBase64URL, "XUFAKrxLKna5cZ2REBfFkg"},
+		{EncBase32, "LVAUAKV4JMVHNOLRTWIRAF6FSI======"},
+	}
+	raw, _ := Hash(MD5, []byte("hello"), true)
+	for _, tt := range tests {
+		res, err := HashEncoded(MD5, []byte("hello"), tt.enc)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, string(res))
+
+		s, err := HashStringEncoded(MD5, "hello", tt.enc)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, s)
+
+		decoded, err := DecodeHash(res, tt.enc)
+		assert.Nil(t, err)
+		assert.Equal(t, raw, decoded)
+		assert.True(t, HashEqual(raw, res, tt.enc))
+		assert.True(t, HashVerify(MD5, []byte("hello"), res, tt.enc))
+		assert.False(t, HashVerify(MD5, []byte("hello!"), res, tt.enc))
+	}
+
+	res, err := HashEncoded(MD5, []byte("hello"), EncRaw)
+	assert.Nil(t, err)
+	assert.Equal(t, raw, res)
+
+	res, err = HashEncoded(SHA256, []byte("hello"), EncBase64URL)
+	assert.Nil(t, err)
+	assert.Equal(t, "LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ", string(res))
+
+	_, err = HashEncoded(MD5, []byte("hello"), HashEncoding(99))
+	assert.NotNil(t, err)
+	_, err = HashEncoded(HashAlgo(99), []byte("hello"), EncHex)
+	assert.NotNil(t, err)
+
+	res, err = HashFileEncoded(MD5, "testdata/files/file1.txt", EncHex)
+	assert.Nil(t, err)
+	old, _ := HashFile(MD5, "testdata/files/file1.txt", false)
+	assert.Equal(t, old, res)
+}
+
+func TestDecodeHash(t *testing.T) {
+	raw, _ := Hash(MD5, []byte("hello"), true)
+
+	// 十六进制不区分大小写，base64/base32 的填充可省略
+	for _, tt := range []struct {
+		digest string
+		enc    HashEncoding
+	}{
+		{"5D41402ABC4B2A76B9719D911017C592", EncHex},
+		{"5d41402abc4b2a76b9719d911017c592", EncHexUpper},
+		{"XUFAKrxLKna5cZ2REBfFkg", EncBase64},
+		{"XUFAKrxLKna5cZ2REBfFkg==", EncBase64URL},
+		{"LVAUAKV4JMVHNOLRTWIRAF6FSI", EncBase32},
+		{strings.ToLower("LVAUAKV4JMVHNOLRTWIRAF6FSI======"), EncBase32},
+	} {
+		decoded, err := DecodeHash([]byte(tt.digest), tt.enc)
+		assert.Nil(t, err, tt.digest)
+		assert.Equal(t, raw, decoded, tt.digest)
+	}
+
+	_, err := DecodeHash([]byte("zz"), EncHex)
+	assert.NotNil(t, err)
+	_, err = DecodeHash([]byte("a*b"), EncBase64)
+	assert.NotNil(t, err)
+	_, err = DecodeHash([]byte("abc"), HashEncoding(99))
+	assert.NotNil(t, err)
+	assert.False(t, HashEqual(raw, []byte("zz"), EncHex))
+	assert.False(t, HashEqual(raw, []byte("5d41402abc4b2a76"), EncHex))
+}
+
+func TestHmacEncoded(t *testing.T) {
+	res, err := HmacEncoded(SHA256, []byte("key"), []byte("The quick brown fox jumps over the lazy dog"), EncHexUpper)
+	assert.Nil(t, err)
+	assert.Equal(t, "F7BC83F430538424B13298E6AA6FB143EF4D59A14946175997479DBC2D1A3CD8", string(res))
+	_, err = HmacEncoded(HashAlgo(99), []byte("key"), []byte("data"), EncHex)
+	assert.NotNil(t, err)
+	_, err = HmacReaderEncoded(CRC32_IEEE, []byte("key"), strings.NewReader("data"), EncHex)
+	assert.NotNil(t, err)
+}
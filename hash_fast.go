@@ -0,0 +1,644 @@
+package xutils
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// 快速的非加密哈希算法，适用于分片、分区、缓存key等场景，不能用于安全相关的用途
+// 实现与官方参考实现一致，可以与其他语言（如Java、php）的结果互通
+
+const (
+	xxPrime32_1 = 0x9E3779B1
+	xxPrime32_2 = 0x85EBCA77
+	xxPrime32_3 = 0xC2B2AE3D
+	xxPrime64_1 = 0x9E3779B185EBCA87
+	xxPrime64_2 = 0xC2B2AE3D27D4EB4F
+	xxPrime64_3 = 0x165667B19E3779F9
+	xxPrime64_4 = 0x85EBCA77C2B2AE63
+	xxPrime64_5 = 0x27D4EB2F165667C5
+)
+
+// XXHash64 计算 xxHash64
+func XXHash64(b []byte, seed uint64) uint64 {
+	h := NewXXHash64(seed)
+	h.Write(b)
+	return h.Sum64()
+}
+
+// NewXXHash64 返回 xxHash64 的 hash.Hash64 实现
+func NewXXHash64(seed uint64) hash.Hash64 {
+	d := &xxh64{seed: seed}
+	d.Reset()
+	return d
+}
+
+type xxh64 struct {
+	seed  uint64
+	v     [4]uint64
+	total uint64
+	buf   [32]byte
+	n     int
+}
+
+func (d *xxh64) Reset() {
+	d.v = [4]uint64{d.seed + xxPrime64_1 + xxPrime64_2, d.seed + xxPrime64_2, d.seed, d.seed - xxPrime64_1}
+	d.total = 0
+	d.n = 0
+}
+
+func (d *xxh64) Size() int      { return 8 }
+func (d *xxh64) BlockSize() int { return 32 }
+
+func (d *xxh64) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+	if d.n+len(b) < 32 {
+		d.n += copy(d.buf[d.n:], b)
+		return n, nil
+	}
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		d.stripe(d.buf[:])
+		b = b[c:]
+		d.n = 0
+	}
+	for ; len(b) >= 32; b = b[32:] {
+		d.stripe(b)
+	}
+	d.n = copy(d.buf[:], b)
+	return n, nil
+}
+
+func (d *xxh64) stripe(b []byte) {
+	for i := range d.v {
+		d.v[i] = xxh64Round(d.v[i], binary.LittleEndian.Uint64(b[i*8:]))
+	}
+}
+
+func (d *xxh64) Sum64() uint64 {
+	var h uint64
+	if d.total >= 32 {
+		h = bits.RotateLeft64(d.v[0], 1) + bits.RotateLeft64(d.v[1], 7) +
+			bits.RotateLeft64(d.v[2], 12) + bits.RotateLeft64(d.v[3], 18)
+		for _, v := range d.v {
+			h = xxh64MergeRound(h, v)
+		}
+	} else {
+		h = d.seed + xxPrime64_5
+	}
+	h += d.total
+
+	b := d.buf[:d.n]
+	for ; len(b) >= 8; b = b[8:] {
+		h ^= xxh64Round(0, binary.LittleEndian.Uint64(b))
+		h = bits.RotateLeft64(h, 27)*xxPrime64_1 + xxPrime64_4
+	}
+	if len(b) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(b)) * xxPrime64_1
+		h = bits.RotateLeft64(h, 23)*xxPrime64_2 + xxPrime64_3
+		b = b[4:]
+	}
+	for _, c := range b {
+		h ^= uint64(c) * xxPrime64_5
+		h = bits.RotateLeft64(h, 11) * xxPrime64_1
+	}
+	return xxh64Avalanche(h)
+}
+
+func (d *xxh64) Sum(b []byte) []byte {
+	return appendUint64(b, d.Sum64())
+}
+
+func xxh64Round(acc, input uint64) uint64 {
+	acc += input * xxPrime64_2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime64_1
+}
+
+func xxh64MergeRound(acc, v uint64) uint64 {
+	acc ^= xxh64Round(0, v)
+	return acc*xxPrime64_1 + xxPrime64_4
+}
+
+func xxh64Avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= xxPrime64_2
+	h ^= h >> 29
+	h *= xxPrime64_3
+	h ^= h >> 32
+	return h
+}
+
+// xxh3Secret XXH3 默认的192字节密钥
+var xxh3Secret = [xxh3SecretSize]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+const (
+	xxh3SecretSize      = 192
+	xxh3StripeLen       = 64
+	xxh3StripesPerBlock = (xxh3SecretSize - xxh3StripeLen) / 8
+	xxh3BlockLen        = xxh3StripeLen * xxh3StripesPerBlock
+	xxh3BufferSize      = 256
+	xxh3PrimeMx1        = 0x165667919E3779F9
+	xxh3PrimeMx2        = 0x9FB21C651E98DF25
+)
+
+// XXH3Hash64 计算 XXH3 64位哈希
+func XXH3Hash64(b []byte, seed uint64) uint64 {
+	n := len(b)
+	secret := xxh3Secret[:]
+	switch {
+	case n <= 16:
+		return xxh3Len0To16(b, secret, seed)
+	case n <= 128:
+		acc := uint64(n) * xxPrime64_1
+		if n > 32 {
+			if n > 64 {
+				if n > 96 {
+					acc += xxh3Mix16(b[48:], secret[96:], seed)
+					acc += xxh3Mix16(b[n-64:], secret[112:], seed)
+				}
+				acc += xxh3Mix16(b[32:], secret[64:], seed)
+				acc += xxh3Mix16(b[n-48:], secret[80:], seed)
+			}
+			acc += xxh3Mix16(b[16:], secret[32:], seed)
+			acc += xxh3Mix16(b[n-32:], secret[48:], seed)
+		}
+		acc += xxh3Mix16(b, secret, seed)
+		acc += xxh3Mix16(b[n-16:], secret[16:], seed)
+		return xxh3Avalanche(acc)
+	case n <= 240:
+		acc := uint64(n) * xxPrime64_1
+		for i := 0; i < 8; i++ {
+			acc += xxh3Mix16(b[16*i:], secret[16*i:], seed)
+		}
+		acc = xxh3Avalanche(acc)
+		for i := 8; i < n/16; i++ {
+			acc += xxh3Mix16(b[16*i:], secret[16*(i-8)+3:], seed)
+		}
+		// 最后16字节使用 secret[136-17:]
+		acc += xxh3Mix16(b[n-16:], secret[119:], seed)
+		return xxh3Avalanche(acc)
+	}
+
+	if seed != 0 {
+		secret = xxh3DeriveSecret(seed)
+	}
+	acc := xxh3InitAcc()
+	blocks := (n - 1) / xxh3BlockLen
+	for i := 0; i < blocks; i++ {
+		xxh3Accumulate(&acc, b[i*xxh3BlockLen:], secret, xxh3StripesPerBlock)
+		xxh3Scramble(&acc, secret[xxh3SecretSize-xxh3StripeLen:])
+	}
+	stripes := ((n - 1) - xxh3BlockLen*blocks) / xxh3StripeLen
+	xxh3Accumulate(&acc, b[blocks*xxh3BlockLen:], secret, stripes)
+	xxh3Accumulate512(&acc, b[n-xxh3StripeLen:], secret[xxh3SecretSize-xxh3StripeLen-7:])
+	return xxh3Merge(&acc, secret, uint64(n))
+}
+
+// NewXXH3Hash64 返回 XXH3 64位哈希的 hash.Hash64 实现，内存占用固定，适合计算大文件
+func NewXXH3Hash64(seed uint64) hash.Hash64 {
+	d := &xxh3{seed: seed, secret: xxh3Secret[:]}
+	if seed != 0 {
+		d.secret = xxh3DeriveSecret(seed)
+	}
+	d.Reset()
+	return d
+}
+
+type xxh3 struct {
+	seed    uint64
+	secret  []byte
+	acc     [8]uint64
+	buf     [xxh3BufferSize]byte
+	n       int    // buf 中的数据长度
+	stripes int    // 当前block已处理的stripe数
+	total   uint64 // 写入的总长度
+}
+
+func (d *xxh3) Reset() {
+	d.acc = xxh3InitAcc()
+	d.n = 0
+	d.stripes = 0
+	d.total = 0
+}
+
+func (d *xxh3) Size() int      { return 8 }
+func (d *xxh3) BlockSize() int { return xxh3StripeLen }
+
+func (d *xxh3) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+	if d.n+len(b) <= xxh3BufferSize {
+		d.n += copy(d.buf[d.n:], b)
+		return n, nil
+	}
+	// 缓冲区中至少保留一个字节，保证最后一个stripe在 Sum 时处理
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		b = b[c:]
+		d.consume(d.buf[:], xxh3BufferSize/xxh3StripeLen)
+		d.n = 0
+	}
+	if len(b) > xxh3BufferSize {
+		i := 0
+		for len(b)-i > xxh3BufferSize {
+			d.consume(b[i:], xxh3BufferSize/xxh3StripeLen)
+			i += xxh3BufferSize
+		}
+		// 保存最后处理的一个stripe，用于剩余数据不足一个stripe时拼接
+		copy(d.buf[xxh3BufferSize-xxh3StripeLen:], b[i-xxh3StripeLen:i])
+		b = b[i:]
+	}
+	d.n = copy(d.buf[:], b)
+	return n, nil
+}
+
+func (d *xxh3) consume(b []byte, stripes int) {
+	if xxh3StripesPerBlock-d.stripes <= stripes {
+		toEnd := xxh3StripesPerBlock - d.stripes
+		xxh3Accumulate(&d.acc, b, d.secret[d.stripes*8:], toEnd)
+		xxh3Scramble(&d.acc, d.secret[xxh3SecretSize-xxh3StripeLen:])
+		xxh3Accumulate(&d.acc, b[toEnd*xxh3StripeLen:], d.secret, stripes-toEnd)
+		d.stripes = stripes - toEnd
+	} else {
+		xxh3Accumulate(&d.acc, b, d.secret[d.stripes*8:], stripes)
+		d.stripes += stripes
+	}
+}
+
+func (d *xxh3) Sum64() uint64 {
+	if d.total <= 240 {
+		return XXH3Hash64(d.buf[:d.total], d.seed)
+	}
+	s := *d
+	var last [xxh3StripeLen]byte
+	if s.n >= xxh3StripeLen {
+		s.consume(s.buf[:s.n], (s.n-1)/xxh3StripeLen)
+		copy(last[:], s.buf[s.n-xxh3StripeLen:s.n])
+	} else {
+		// 剩余数据不足一个stripe，与上一次处理的数据末尾拼接
+		catchup := xxh3StripeLen - s.n
+		copy(last[:], s.buf[xxh3BufferSize-catchup:])
+		copy(last[catchup:], s.buf[:s.n])
+	}
+	xxh3Accumulate512(&s.acc, last[:], s.secret[xxh3SecretSize-xxh3StripeLen-7:])
+	return xxh3Merge(&s.acc, s.secret, s.total)
+}
+
+func (d *xxh3) Sum(b []byte) []byte {
+	return appendUint64(b, d.Sum64())
+}
+
+func xxh3Len0To16(b []byte, secret []byte, seed uint64) uint64 {
+	n := len(b)
+	switch {
+	case n > 8:
+		bitflip1 := (le64(secret[24:]) ^ le64(secret[32:])) + seed
+		bitflip2 := (le64(secret[40:]) ^ le64(secret[48:])) - seed
+		lo := le64(b) ^ bitflip1
+		hi := le64(b[n-8:]) ^ bitflip2
+		acc := uint64(n) + bits.ReverseBytes64(lo) + hi + mulFold64(lo, hi)
+		return xxh3Avalanche(acc)
+	case n >= 4:
+		seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+		input1 := le32(b)
+		input2 := le32(b[n-4:])
+		bitflip := (le64(secret[8:]) ^ le64(secret[16:])) - seed
+		keyed := (uint64(input2) + uint64(input1)<<32) ^ bitflip
+		return xxh3Rrmxmx(keyed, uint64(n))
+	case n > 0:
+		combined := uint32(b[0])<<16 | uint32(b[n>>1])<<24 | uint32(b[n-1]) | uint32(n)<<8
+		bitflip := uint64(le32(secret)^le32(secret[4:])) + seed
+		return xxh64Avalanche(uint64(combined) ^ bitflip)
+	}
+	return xxh64Avalanche(seed ^ le64(secret[56:]) ^ le64(secret[64:]))
+}
+
+func xxh3Mix16(b []byte, secret []byte, seed uint64) uint64 {
+	return mulFold64(le64(b)^(le64(secret)+seed), le64(b[8:])^(le64(secret[8:])-seed))
+}
+
+func xxh3InitAcc() [8]uint64 {
+	return [8]uint64{xxPrime32_3, xxPrime64_1, xxPrime64_2, xxPrime64_3, xxPrime64_4, xxPrime32_2, xxPrime64_5, xxPrime32_1}
+}
+
+func xxh3Accumulate(acc *[8]uint64, b []byte, secret []byte, stripes int) {
+	for i := 0; i < stripes; i++ {
+		xxh3Accumulate512(acc, b[i*xxh3StripeLen:], secret[i*8:])
+	}
+}
+
+func xxh3Accumulate512(acc *[8]uint64, b []byte, secret []byte) {
+	for i := 0; i < 8; i++ {
+		v := le64(b[8*i:])
+		k := v ^ le64(secret[8*i:])
+		acc[i^1] += v
+		acc[i] += (k & 0xffffffff) * (k >> 32)
+	}
+}
+
+func xxh3Scramble(acc *[8]uint64, secret []byte) {
+	for i := range acc {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= le64(secret[8*i:])
+		a *= xxPrime32_1
+		acc[i] = a
+	}
+}
+
+func xxh3Merge(acc *[8]uint64, secret []byte, n uint64) uint64 {
+	result := n * xxPrime64_1
+	for i := 0; i < 4; i++ {
+		result += mulFold64(acc[2*i]^le64(secret[11+16*i:]), acc[2*i+1]^le64(secret[11+16*i+8:]))
+	}
+	return xxh3Avalanche(result)
+}
+
+func xxh3DeriveSecret(seed uint64) []byte {
+	secret := make([]byte, xxh3SecretSize)
+	for i := 0; i < xxh3SecretSize; i += 16 {
+		binary.LittleEndian.PutUint64(secret[i:], le64(xxh3Secret[i:])+seed)
+		binary.LittleEndian.PutUint64(secret[i+8:], le64(xxh3Secret[i+8:])-seed)
+	}
+	return secret
+}
+
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= xxh3PrimeMx1
+	h ^= h >> 32
+	return h
+}
+
+func xxh3Rrmxmx(h uint64, n uint64) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= xxh3PrimeMx2
+	h ^= (h >> 35) + n
+	h *= xxh3PrimeMx2
+	h ^= h >> 28
+	return h
+}
+
+func mulFold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func le64(b []byte) uint64 {
+	return binary.LittleEndian.Uint64(b)
+}
+
+func le32(b []byte) uint32 {
+	return binary.LittleEndian.Uint32(b)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+const (
+	murmur32C1 = 0xcc9e2d51
+	murmur32C2 = 0x1b873593
+	murmur64C1 = 0x87c37b91114253d5
+	murmur64C2 = 0x4cf5ad432745937f
+)
+
+// Murmur3Hash32 计算 MurmurHash3 x86 32位哈希
+func Murmur3Hash32(b []byte, seed uint32) uint32 {
+	h := NewMurmur3Hash32(seed)
+	h.Write(b)
+	return h.Sum32()
+}
+
+// NewMurmur3Hash32 返回 MurmurHash3 x86 32位的 hash.Hash32 实现
+func NewMurmur3Hash32(seed uint32) hash.Hash32 {
+	return &murmur32{seed: seed, h: seed}
+}
+
+type murmur32 struct {
+	seed  uint32
+	h     uint32
+	total uint64
+	buf   [4]byte
+	n     int
+}
+
+func (d *murmur32) Reset() {
+	d.h = d.seed
+	d.total = 0
+	d.n = 0
+}
+
+func (d *murmur32) Size() int      { return 4 }
+func (d *murmur32) BlockSize() int { return 4 }
+
+func (d *murmur32) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		d.n += c
+		b = b[c:]
+		if d.n < 4 {
+			return n, nil
+		}
+		d.block(le32(d.buf[:]))
+		d.n = 0
+	}
+	for ; len(b) >= 4; b = b[4:] {
+		d.block(le32(b))
+	}
+	d.n = copy(d.buf[:], b)
+	return n, nil
+}
+
+func (d *murmur32) block(k uint32) {
+	k *= murmur32C1
+	k = bits.RotateLeft32(k, 15)
+	k *= murmur32C2
+	d.h ^= k
+	d.h = bits.RotateLeft32(d.h, 13)
+	d.h = d.h*5 + 0xe6546b64
+}
+
+func (d *murmur32) Sum32() uint32 {
+	h := d.h
+	var k uint32
+	switch d.n {
+	case 3:
+		k ^= uint32(d.buf[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(d.buf[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(d.buf[0])
+		k *= murmur32C1
+		k = bits.RotateLeft32(k, 15)
+		k *= murmur32C2
+		h ^= k
+	}
+	h ^= uint32(d.total)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+func (d *murmur32) Sum(b []byte) []byte {
+	return appendUint32(b, d.Sum32())
+}
+
+// Murmur3Hash128 计算 MurmurHash3 x64 128位哈希，返回高64位 h1 和低64位 h2
+func Murmur3Hash128(b []byte, seed uint32) (uint64, uint64) {
+	h := NewMurmur3Hash128(seed)
+	h.Write(b)
+	return h.Sum128()
+}
+
+// Murmur3Hash128Hasher MurmurHash3 x64 128位哈希的流式接口
+// Sum 按大端序输出 h1 + h2，Sum64 返回 h1
+type Murmur3Hash128Hasher interface {
+	hash.Hash64
+	Sum128() (uint64, uint64)
+}
+
+// NewMurmur3Hash128 返回 MurmurHash3 x64 128位哈希的流式实现
+func NewMurmur3Hash128(seed uint32) Murmur3Hash128Hasher {
+	return &murmur128{seed: seed, h1: uint64(seed), h2: uint64(seed)}
+}
+
+type murmur128 struct {
+	seed   uint32
+	h1, h2 uint64
+	total  uint64
+	buf    [16]byte
+	n      int
+}
+
+func (d *murmur128) Reset() {
+	d.h1, d.h2 = uint64(d.seed), uint64(d.seed)
+	d.total = 0
+	d.n = 0
+}
+
+func (d *murmur128) Size() int      { return 16 }
+func (d *murmur128) BlockSize() int { return 16 }
+
+func (d *murmur128) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		d.n += c
+		b = b[c:]
+		if d.n < 16 {
+			return n, nil
+		}
+		d.block(d.buf[:])
+		d.n = 0
+	}
+	for ; len(b) >= 16; b = b[16:] {
+		d.block(b)
+	}
+	d.n = copy(d.buf[:], b)
+	return n, nil
+}
+
+func (d *murmur128) block(b []byte) {
+	k1, k2 := le64(b), le64(b[8:])
+	k1 *= murmur64C1
+	k1 = bits.RotateLeft64(k1, 31)
+	k1 *= murmur64C2
+	d.h1 ^= k1
+	d.h1 = bits.RotateLeft64(d.h1, 27)
+	d.h1 += d.h2
+	d.h1 = d.h1*5 + 0x52dce729
+
+	k2 *= murmur64C2
+	k2 = bits.RotateLeft64(k2, 33)
+	k2 *= murmur64C1
+	d.h2 ^= k2
+	d.h2 = bits.RotateLeft64(d.h2, 31)
+	d.h2 += d.h1
+	d.h2 = d.h2*5 + 0x38495ab5
+}
+
+func (d *murmur128) Sum128() (uint64, uint64) {
+	h1, h2 := d.h1, d.h2
+	var k1, k2 uint64
+	tail := d.buf[:d.n]
+	for i := len(tail) - 1; i >= 8; i-- {
+		k2 ^= uint64(tail[i]) << (8 * (i - 8))
+	}
+	if len(tail) > 8 {
+		k2 *= murmur64C2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= murmur64C1
+		h2 ^= k2
+	}
+	for i := 0; i < len(tail) && i < 8; i++ {
+		k1 ^= uint64(tail[i]) << (8 * i)
+	}
+	if len(tail) > 0 {
+		k1 *= murmur64C1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= murmur64C2
+		h1 ^= k1
+	}
+
+	h1 ^= d.total
+	h2 ^= d.total
+	h1 += h2
+	h2 += h1
+	h1 = murmurFmix64(h1)
+	h2 = murmurFmix64(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+func (d *murmur128) Sum64() uint64 {
+	h1, _ := d.Sum128()
+	return h1
+}
+
+func (d *murmur128) Sum(b []byte) []byte {
+	h1, h2 := d.Sum128()
+	return appendUint64(appendUint64(b, h1), h2)
+}
+
+func murmurFmix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
@@ -0,0 +1,112 @@
+package xutils
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"hash"
+	"testing"
+)
+
+type fastHashVector struct {
+	input  string
+	seed   uint64
+	xxh64  uint64
+	xxh3   uint64
+	mm32   uint32
+	mm128a uint64
+	mm128b uint64
+}
+
+func fastHashVectors() []fastHashVector {
+	long := make([]byte, 1000)
+	for i := range long {
+		long[i] = byte(i * 7)
+	}
+	fox := "The quick brown fox jumps over the lazy dog"
+	return []fastHashVector{
+		{"", 0, 0xef46db3751d8e999, 0x2d06800538d394c2, 0x00000000, 0x0000000000000000, 0x0000000000000000},
+		{"", 42, 0x98b1582b0977e704, 0xb029411ff43d84d2, 0x087fcd5c, 0xf02aa77dfa1b8523, 0xd1016610da11cbb9},
+		{"a", 0, 0xd24ec4f1a98c6e5b, 0xe6c632b61e964e1f, 0x3c2569b2, 0x85555565f6597889, 0xe6b53a48510e895a},
+		{"a", 42, 0x88e4fe59adf7b0cc, 0x4c437dd47f0716f4, 0xb2e5a263, 0x28259ca4fdf626b0, 0x25ebca9125f82b15},
+		{"hello", 0, 0x26c7827d889f6da3, 0x9555e8555c62dcfd, 0x248bfa47, 0xcbd8a7b341bd9b02, 0x5b1e906a48ae1d19},
+		{"hello", 42, 0xc3629e6318d53932, 0xbafa072f07db7937, 0xe2dbd2e1, 0xc4b8b3c960af6f08, 0x2334b875b0efbc7a},
+		{fox, 0, 0x0b242d361fda71bc, 0xce7d19a5418fb365, 0x2e4ff723, 0xe34bbc7bbc071b6c, 0x7a433ca9c49a9347},
+		{fox, 42, 0xaa9f288a8baa3d3f, 0xb4a3f3c36b3c7d26, 0x347ca102, 0x740dcf93fe0bd5d7, 0xc4546cf4ec705c8f},
+		{string(long), 0, 0x25275608a9cfc168, 0x10ad30264426c830, 0x3db78852, 0x0dc15cd8f7246c77, 0x6ad442de1cdd03d8},
+		{string(long), 42, 0x6d70f8faa18af724, 0x715c5bbc12530d92, 0x40b0c26c, 0xa78780f90c14fa5b, 0x062f8168739f24a1},
+	}
+}
+
+// writeChunks 按固定大小分多次写入，用于验证流式计算与一次性计算结果一致
+func writeChunks(h hash.Hash, b []byte, size int) {
+	for len(b) > size {
+		h.Write(b[:size])
+		b = b[size:]
+	}
+	h.Write(b)
+}
+
+func TestXXHash64(t *testing.T) {
+	for _, v := range fastHashVectors() {
+		assert.Equal(t, v.xxh64, XXHash64([]byte(v.input), v.seed), "len=%d seed=%d", len(v.input), v.seed)
+		for _, size := range []int{1, 7, 32, 100} {
+			h := NewXXHash64(v.seed)
+			writeChunks(h, []byte(v.input), size)
+			assert.Equal(t, v.xxh64, h.Sum64())
+		}
+	}
+	h := NewXXHash64(0)
+	h.Write([]byte("abc"))
+	h.Reset()
+	assert.Equal(t, uint64(0xef46db3751d8e999), h.Sum64())
+	assert.Equal(t, "ef46db3751d8e999", hex.EncodeToString(h.Sum(nil)))
+}
+
+func TestXXH3Hash64(t *testing.T) {
+	for _, v := range fastHashVectors() {
+		assert.Equal(t, v.xxh3, XXH3Hash64([]byte(v.input), v.seed), "len=%d seed=%d", len(v.input), v.seed)
+		for _, size := range []int{1, 7, 64, 255} {
+			h := NewXXH3Hash64(v.seed)
+			writeChunks(h, []byte(v.input), size)
+			assert.Equal(t, v.xxh3, h.Sum64())
+		}
+	}
+}
+
+func TestMurmur3Hash32(t *testing.T) {
+	for _, v := range fastHashVectors() {
+		assert.Equal(t, v.mm32, Murmur3Hash32([]byte(v.input), uint32(v.seed)), "len=%d seed=%d", len(v.input), v.seed)
+		for _, size := range []int{1, 3, 16} {
+			h := NewMurmur3Hash32(uint32(v.seed))
+			writeChunks(h, []byte(v.input), size)
+			assert.Equal(t, v.mm32, h.Sum32())
+		}
+	}
+}
+
+func TestMurmur3Hash128(t *testing.T) {
+	for _, v := range fastHashVectors() {
+		h1, h2 := Murmur3Hash128([]byte(v.input), uint32(v.seed))
+		assert.Equal(t, v.mm128a, h1, "len=%d seed=%d", len(v.input), v.seed)
+		assert.Equal(t, v.mm128b, h2, "len=%d seed=%d", len(v.input), v.seed)
+		for _, size := range []int{1, 9, 16, 100} {
+			h := NewMurmur3Hash128(uint32(v.seed))
+			writeChunks(h, []byte(v.input), size)
+			assert.Equal(t, v.mm128a, h.Sum64())
+		}
+	}
+	h := NewMurmur3Hash128(0)
+	h.Write([]byte("hello"))
+	assert.Equal(t, "cbd8a7b341bd9b025b1e906a48ae1d19", hex.EncodeToString(h.Sum(nil)))
+}
+
+func TestFastHashAlgo(t *testing.T) {
+	s, err := HashString(XXH64, "hello", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "26c7827d889f6da3", s)
+	s, _ = HashString(MURMUR3_32, "hello", false)
+	assert.Equal(t, "248bfa47", s)
+	algo, err := ParseHashAlgo("XXH3")
+	assert.Nil(t, err)
+	assert.Equal(t, XXH3_64, algo)
+}
@@ -0,0 +1,151 @@
+package xutils
+
+import (
+	stdcontext "context" // 包内已有 context 类型（html_strip_tags.go）
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// HashFilesOptions HashFiles 的可选参数
+// OnProgress 和 OnResult 会被串行调用，回调中不需要额外加锁，但应尽快返回以免阻塞计算
+type HashFilesOptions struct {
+	Workers    int                          // 并发数，默认为 CPU 核数
+	Context    stdcontext.Context           // 取消后会尽快停止，包括正在计算的大文件
+	OnProgress func(files int, bytes int64) // 进度回调，参数为已完成的文件数和已读取的总字节数
+	OnResult   func(res HashFileResult)     // 每个文件计算完成（或失败）时回调，顺序与 paths 不一定一致
+}
+
+// HashFileResult 单个文件的哈希结果
+type HashFileResult struct {
+	Path string
+	Sum  []byte // 原始字节
+	Size int64
+	Err  error
+}
+
+// HashFiles 使用有限数量的 goroutine 并发计算多个文件的哈希值
+// 返回的结果与 paths 一一对应，单个文件的错误记录在 HashFileResult.Err 中；
+// 被取消时返回 Context 的错误，未计算的文件 Err 也为该错误
+func HashFiles(paths []string, algo HashAlgo, opts *HashFilesOptions) ([]HashFileResult, error) {
+	if opts == nil {
+		opts = &HashFilesOptions{}
+	}
+	if _, err := newHash(algo); err != nil {
+		return nil, err
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = stdcontext.Background()
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	results := make([]HashFileResult, len(paths))
+	finished := make([]bool, len(paths))
+	progress := &hashFilesProgress{opts: opts}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = hashFileContext(ctx, algo, paths[idx], progress)
+				finished[idx] = true
+				progress.done(results[idx])
+			}
+		}()
+	}
+feed:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i := range results {
+			if !finished[i] {
+				results[i] = HashFileResult{Path: paths[i], Err: err}
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+func hashFileContext(ctx stdcontext.Context, algo HashAlgo, path string, progress *hashFilesProgress) HashFileResult {
+	res := HashFileResult{Path: path}
+	if res.Err = ctx.Err(); res.Err != nil {
+		return res
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer f.Close()
+	h, _ := newHash(algo)
+	res.Size, res.Err = io.Copy(h, &hashFilesReader{ctx: ctx, r: f, progress: progress})
+	if res.Err == nil {
+		res.Sum = h.Sum(nil)
+	}
+	return res
+}
+
+// hashFilesReader 读取时检查是否已取消，并汇报读取进度
+type hashFilesReader struct {
+	ctx      stdcontext.Context
+	r        io.Reader
+	progress *hashFilesProgress
+}
+
+func (r *hashFilesReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.progress.add(int64(n))
+	}
+	return n, err
+}
+
+type hashFilesProgress struct {
+	mu    sync.Mutex
+	opts  *HashFilesOptions
+	files int
+	bytes int64
+}
+
+func (p *hashFilesProgress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes += n
+	if p.opts.OnProgress != nil {
+		p.opts.OnProgress(p.files, p.bytes)
+	}
+}
+
+func (p *hashFilesProgress) done(res HashFileResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files++
+	if p.opts.OnResult != nil {
+		p.opts.OnResult(res)
+	}
+	if p.opts.OnProgress != nil {
+		p.opts.OnProgress(p.files, p.bytes)
+	}
+}
@@ -0,0 +1,95 @@
+package xutils
+
+import (
+	stdcontext "context"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashFiles(t *testing.T) {
+	dir, clean := TempDir("hashfiles")
+	defer clean()
+	paths := make([]string, 0)
+	var total int64
+	for i := 0; i < 20; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("%02d.txt", i))
+		data := make([]byte, i*1000)
+		assert.Nil(t, os.WriteFile(p, data, 0644))
+		paths = append(paths, p)
+		total += int64(len(data))
+	}
+	paths = append(paths, filepath.Join(dir, "not_exists.txt"))
+
+	var (
+		lastFiles int
+		lastBytes int64
+		seen      = make(map[string]bool)
+	)
+	results, err := HashFiles(paths, SHA256, &HashFilesOptions{
+		Workers: 4,
+		OnProgress: func(files int, bytes int64) {
+			assert.True(t, files >= lastFiles && bytes >= lastBytes)
+			lastFiles, lastBytes = files, bytes
+		},
+		OnResult: func(res HashFileResult) {
+			seen[res.Path] = true
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, len(paths), len(results))
+	assert.Equal(t, len(paths), len(seen))
+	assert.Equal(t, len(paths), lastFiles)
+	assert.Equal(t, total, lastBytes)
+
+	for i, res := range results[:20] {
+		assert.Equal(t, paths[i], res.Path)
+		assert.Nil(t, res.Err)
+		assert.Equal(t, int64(i*1000), res.Size)
+		want, _ := HashFile(SHA256, paths[i], false)
+		assert.Equal(t, string(want), hex.EncodeToString(res.Sum))
+	}
+	assert.NotNil(t, results[20].Err)
+
+	results, err = HashFiles(nil, MD5, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results))
+	_, err = HashFiles(paths, HashAlgo(99), nil)
+	assert.NotNil(t, err)
+}
+
+func TestHashFilesCancel(t *testing.T) {
+	dir, clean := TempDir("hashfiles")
+	defer clean()
+	paths := make([]string, 0)
+	for i := 0; i < 10; i++ {
+		p := filepath.Join(dir, fmt.Sprintf("%02d.bin", i))
+		assert.Nil(t, os.WriteFile(p, make([]byte, 1<<20), 0644))
+		paths = append(paths, p)
+	}
+
+	// 读取到第一块数据后取消，后续文件不再计算
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	defer cancel()
+	results, err := HashFiles(paths, SHA256, &HashFilesOptions{
+		Workers: 2,
+		Context: ctx,
+		OnProgress: func(files int, bytes int64) {
+			cancel()
+		},
+	})
+	assert.Equal(t, stdcontext.Canceled, err)
+	assert.Equal(t, len(paths), len(results))
+	cancelled := 0
+	for i, res := range results {
+		assert.Equal(t, paths[i], res.Path)
+		if res.Err == stdcontext.Canceled {
+			cancelled++
+			assert.Nil(t, res.Sum)
+		}
+	}
+	assert.True(t, cancelled >= len(paths)-2)
+}
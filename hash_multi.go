@@ -0,0 +1,101 @@
+package xutils
+
+import (
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// MultiHasher 一次读取同时计算多种哈希值，实现了 io.Writer，可以配合 io.TeeReader、io.MultiWriter 使用
+// 同时会统计写入的字节数和CRC32（IEEE）
+type MultiHasher struct {
+	hashes map[HashAlgo]hash.Hash
+	crc    hash.Hash32
+	count  int64
+}
+
+// NewMultiHasher 创建 MultiHasher，algos 为需要计算的哈希算法
+func NewMultiHasher(algos ...HashAlgo) (*MultiHasher, error) {
+	m := &MultiHasher{
+		hashes: make(map[HashAlgo]hash.Hash, len(algos)),
+		crc:    crc32.NewIEEE(),
+	}
+	for _, algo := range algos {
+		h, err := newHash(algo)
+		if err != nil {
+			return nil, err
+		}
+		m.hashes[algo] = h
+	}
+	return m, nil
+}
+
+// Write 写入数据，总是返回 len(p), nil
+func (m *MultiHasher) Write(p []byte) (int, error) {
+	for _, h := range m.hashes {
+		h.Write(p)
+	}
+	m.crc.Write(p)
+	m.count += int64(len(p))
+	return len(p), nil
+}
+
+// Sum 返回指定算法的哈希值（原始字节），未指定的算法返回nil
+func (m *MultiHasher) Sum(algo HashAlgo) []byte {
+	h, ok := m.hashes[algo]
+	if !ok {
+		return nil
+	}
+	return h.Sum(nil)
+}
+
+// Sums 返回所有算法的哈希值（原始字节）
+func (m *MultiHasher) Sums() map[HashAlgo][]byte {
+	sums := make(map[HashAlgo][]byte, len(m.hashes))
+	for algo, h := range m.hashes {
+		sums[algo] = h.Sum(nil)
+	}
+	return sums
+}
+
+// CRC32 返回写入数据的CRC32（IEEE）
+func (m *MultiHasher) CRC32() uint32 {
+	return m.crc.Sum32()
+}
+
+// Count 返回写入的字节数
+func (m *MultiHasher) Count() int64 {
+	return m.count
+}
+
+// Reset 重置所有状态
+func (m *MultiHasher) Reset() {
+	for _, h := range m.hashes {
+		h.Reset()
+	}
+	m.crc.Reset()
+	m.count = 0
+}
+
+// HashReaderMulti 读取一次 rd 同时计算多种哈希值，返回原始字节
+func HashReaderMulti(rd io.Reader, algos ...HashAlgo) (map[HashAlgo][]byte, error) {
+	m, err := NewMultiHasher(algos...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(m, rd); err != nil {
+		return nil, err
+	}
+	return m.Sums(), nil
+}
+
+// HashFileMulti 读取一次文件同时计算多种哈希值，返回原始字节
+func HashFileMulti(filename string, algos ...HashAlgo) (map[HashAlgo][]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return HashReaderMulti(f, algos...)
+}
@@ -0,0 +1,51 @@
+package xutils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestHashReaderMulti(t *testing.T) {
+	sums, err := HashReaderMulti(bytes.NewReader(data), MD5, SHA1, SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(sums))
+	assert.Equal(t, "c6b65ead773ff0516de556973080ec76", hex.EncodeToString(sums[MD5]))
+	assert.Equal(t, "ffb5eccfcb365fc84a85fcb0291647eeefc83b30", hex.EncodeToString(sums[SHA1]))
+	assert.Equal(t, "2aa2147e3bfbfdf4c8ff113d64ec7c49e145b9d49d6e7a1e78b2839b4cdca838", hex.EncodeToString(sums[SHA256]))
+
+	_, err = HashReaderMulti(bytes.NewReader(data), MD5, HashAlgo(99))
+	assert.NotNil(t, err)
+
+	sums, err = HashFileMulti("testdata/files/file1.txt", MD5)
+	assert.Nil(t, err)
+	md5, _ := HashFile(MD5, "testdata/files/file1.txt", true)
+	assert.Equal(t, md5, sums[MD5])
+	_, err = HashFileMulti("testdata/files/not_exists.txt", MD5)
+	assert.NotNil(t, err)
+}
+
+func TestMultiHasher(t *testing.T) {
+	m, err := NewMultiHasher(MD5, SHA256)
+	assert.Nil(t, err)
+
+	// 在读取数据的同时计算哈希
+	var dst bytes.Buffer
+	_, err = io.Copy(&dst, io.TeeReader(bytes.NewReader(data), m))
+	assert.Nil(t, err)
+	assert.Equal(t, data, dst.Bytes())
+
+	assert.Equal(t, int64(len(data)), m.Count())
+	assert.Equal(t, CRC32(data), m.CRC32())
+	assert.Equal(t, "c6b65ead773ff0516de556973080ec76", hex.EncodeToString(m.Sum(MD5)))
+	assert.Nil(t, m.Sum(SHA1))
+	assert.Equal(t, 2, len(m.Sums()))
+
+	m.Reset()
+	assert.Equal(t, int64(0), m.Count())
+	assert.Equal(t, uint32(0), m.CRC32())
+	md5, _ := Hash(MD5, nil, true)
+	assert.Equal(t, md5, m.Sum(MD5))
+}
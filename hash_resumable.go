@@ -0,0 +1,175 @@
+package xutils
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"os"
+)
+
+var ErrHashStateUnsupported = errors.New("hash state is not serializable")
+
+// DefaultHashCheckpointInterval HashFileResumable 每读取多少字节保存一次进度
+var DefaultHashCheckpointInterval int64 = 64 << 20
+
+const hashStateVersion = 1
+
+// Hasher 可以保存和恢复中间状态的哈希计算器，实现了 hash.Hash
+// 只有实现了 encoding.BinaryMarshaler 的算法（如 MD5、SHA1、SHA2、BLAKE2、CRC、FNV）支持保存状态
+type Hasher struct {
+	algo  HashAlgo
+	h     hash.Hash
+	count int64
+}
+
+// NewHasher 创建 Hasher
+func NewHasher(algo HashAlgo) (*Hasher, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	return &Hasher{algo: algo, h: h}, nil
+}
+
+// Algo 返回哈希算法
+func (h *Hasher) Algo() HashAlgo {
+	return h.algo
+}
+
+// Count 返回已写入的字节数
+func (h *Hasher) Count() int64 {
+	return h.count
+}
+
+// Write 写入数据
+func (h *Hasher) Write(p []byte) (int, error) {
+	n, err := h.h.Write(p)
+	h.count += int64(n)
+	return n, err
+}
+
+// Sum 将当前的哈希值追加到 b 后返回，不影响内部状态
+func (h *Hasher) Sum(b []byte) []byte {
+	return h.h.Sum(b)
+}
+
+// Reset 重置状态
+func (h *Hasher) Reset() {
+	h.h.Reset()
+	h.count = 0
+}
+
+// Size 返回哈希值的字节数
+func (h *Hasher) Size() int {
+	return h.h.Size()
+}
+
+// BlockSize 返回哈希算法的块大小
+func (h *Hasher) BlockSize() int {
+	return h.h.BlockSize()
+}
+
+// MarshalState 导出当前状态，包括算法和已写入的字节数
+// 格式为 version(1) + algo(2) + count(8) + 算法自身的状态
+func (h *Hasher) MarshalState() ([]byte, error) {
+	m, ok := h.h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, ErrHashStateUnsupported
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b := []byte{hashStateVersion, byte(h.algo >> 8), byte(h.algo)}
+	b = appendUint64(b, uint64(h.count))
+	return append(b, state...), nil
+}
+
+// UnmarshalState 从 MarshalState 导出的数据恢复状态，算法必须一致
+func (h *Hasher) UnmarshalState(data []byte) error {
+	if len(data) < 11 || data[0] != hashStateVersion {
+		return errors.New("invalid hash state")
+	}
+	if HashAlgo(binary.BigEndian.Uint16(data[1:])) != h.algo {
+		return errors.New("hash state algorithm mismatch")
+	}
+	u, ok := h.h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return ErrHashStateUnsupported
+	}
+	if err := u.UnmarshalBinary(data[11:]); err != nil {
+		return err
+	}
+	h.count = int64(binary.BigEndian.Uint64(data[3:]))
+	return nil
+}
+
+// HashFileResumable 计算大文件的哈希值（原始字节），计算过程中定期将进度保存到 checkpointPath，
+// 进程中断后再次调用会从上次保存的位置继续计算，完成后删除 checkpointPath，进度文件的权限为 OwnerOnlyFileMode
+// 文件的大小或修改时间发生变化、进度文件损坏时会从头开始计算
+func HashFileResumable(algo HashAlgo, path string, checkpointPath string) ([]byte, error) {
+	h, err := NewHasher(algo)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = h.MarshalState(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// 进度文件格式为 size(8) + mtime(8) + Hasher 的状态
+	header := appendUint64(nil, uint64(info.Size()))
+	header = appendUint64(header, uint64(info.ModTime().UnixNano()))
+
+	if data, err := os.ReadFile(checkpointPath); err == nil {
+		if len(data) > len(header) && string(data[:len(header)]) == string(header) &&
+			h.UnmarshalState(data[len(header):]) == nil && h.Count() <= info.Size() {
+			if _, err = f.Seek(h.Count(), io.SeekStart); err != nil {
+				return nil, err
+			}
+		} else {
+			h.Reset()
+		}
+	}
+
+	interval := DefaultHashCheckpointInterval
+	if interval <= 0 {
+		interval = 64 << 20
+	}
+	for {
+		_, err := io.CopyN(h, f, interval)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Count() >= info.Size() {
+			break
+		}
+		state, err := h.MarshalState()
+		if err != nil {
+			return nil, err
+		}
+		err = writeFileAtomicMode(checkpointPath, OwnerOnlyFileMode, func(w io.Writer) error {
+			_, err := w.Write(append(header, state...))
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
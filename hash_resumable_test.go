@@ -0,0 +1,93 @@
+package xutils
+
+import (
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasherState(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+	for _, algo := range []HashAlgo{MD5, SHA1, SHA256, SHA512, BLAKE2B_256, CRC32_IEEE, FNV1A_64} {
+		h, err := NewHasher(algo)
+		assert.Nil(t, err)
+		h.Write(data[:10])
+		state, err := h.MarshalState()
+		assert.Nil(t, err, algo.String())
+
+		h2, _ := NewHasher(algo)
+		assert.Nil(t, h2.UnmarshalState(state), algo.String())
+		assert.Equal(t, int64(10), h2.Count())
+		h2.Write(data[10:])
+		want, _ := Hash(algo, data, true)
+		assert.Equal(t, want, h2.Sum(nil), algo.String())
+		assert.Equal(t, int64(len(data)), h2.Count())
+	}
+
+	h, _ := NewHasher(SHA256)
+	state, _ := h.MarshalState()
+	h2, _ := NewHasher(MD5)
+	assert.NotNil(t, h2.UnmarshalState(state))
+	assert.NotNil(t, h.UnmarshalState(state[:5]))
+
+	h3, _ := NewHasher(SHA3_256)
+	_, err := h3.MarshalState()
+	assert.Equal(t, ErrHashStateUnsupported, err)
+	_, err = NewHasher(HashAlgo(99))
+	assert.NotNil(t, err)
+}
+
+func TestHashFileResumable(t *testing.T) {
+	old := DefaultHashCheckpointInterval
+	DefaultHashCheckpointInterval = 1000
+	defer func() { DefaultHashCheckpointInterval = old }()
+
+	dir, clean := TempDir("resumable")
+	defer clean()
+	path := filepath.Join(dir, "data.bin")
+	checkpoint := filepath.Join(dir, "data.bin.ckpt")
+	data := make([]byte, 10500)
+	rand.New(rand.NewSource(1)).Read(data)
+	assert.Nil(t, os.WriteFile(path, data, 0644))
+	want := sha256.Sum256(data)
+
+	sum, err := HashFileResumable(SHA256, path, checkpoint)
+	assert.Nil(t, err)
+	assert.Equal(t, want[:], sum)
+	assert.False(t, IsFile(checkpoint))
+
+	// 构造一个已计算了前 4000 字节的进度文件，前缀使用不同的数据，以确认确实是从进度继续计算的
+	info, _ := os.Stat(path)
+	header := appendUint64(nil, uint64(info.Size()))
+	header = appendUint64(header, uint64(info.ModTime().UnixNano()))
+	fake := append(make([]byte, 4000), data[4000:]...)
+	h, _ := NewHasher(SHA256)
+	h.Write(fake[:4000])
+	state, _ := h.MarshalState()
+	assert.Nil(t, os.WriteFile(checkpoint, append(header, state...), 0600))
+	sum, err = HashFileResumable(SHA256, path, checkpoint)
+	assert.Nil(t, err)
+	fakeSum := sha256.Sum256(fake)
+	assert.Equal(t, fakeSum[:], sum)
+	assert.False(t, IsFile(checkpoint))
+
+	// 进度文件损坏或与文件不匹配时从头计算
+	assert.Nil(t, os.WriteFile(checkpoint, []byte("garbage"), 0600))
+	sum, err = HashFileResumable(SHA256, path, checkpoint)
+	assert.Nil(t, err)
+	assert.Equal(t, want[:], sum)
+
+	header[0] ^= 1
+	assert.Nil(t, os.WriteFile(checkpoint, append(header, state...), 0600))
+	sum, err = HashFileResumable(SHA256, path, checkpoint)
+	assert.Nil(t, err)
+	assert.Equal(t, want[:], sum)
+
+	_, err = HashFileResumable(SHA3_256, path, checkpoint)
+	assert.Equal(t, ErrHashStateUnsupported, err)
+	_, err = HashFileResumable(SHA256, filepath.Join(dir, "not_exists"), checkpoint)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,161 @@
+package xutils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultHashRingReplicas 权重为1的节点默认的虚拟节点数
+const DefaultHashRingReplicas = 160
+
+// HashRingOptions HashRing 的可选参数
+type HashRingOptions struct {
+	Replicas int                      // 权重为1的节点对应的虚拟节点数，默认为 DefaultHashRingReplicas
+	HashFunc func(data []byte) uint64 // 哈希函数，默认为 CRC32，也可以使用 XXH3Hash64 等
+}
+
+// HashRing 带虚拟节点的一致性哈希环，可以安全地并发使用
+// 节点通过 fmt.Sprint 转换为字符串后计算在环上的位置，因此同一个节点在不同进程中的位置是一致的，
+// 指针等类型的节点应实现 fmt.Stringer
+type HashRing[T comparable] struct {
+	mu       sync.RWMutex
+	replicas int
+	hashFunc func(data []byte) uint64
+	weights  map[T]int
+	points   []hashRingPoint[T]
+}
+
+type hashRingPoint[T comparable] struct {
+	hash uint64
+	key  string
+	node T
+}
+
+// NewHashRing 创建一致性哈希环，opts 可以为 nil
+func NewHashRing[T comparable](opts *HashRingOptions) *HashRing[T] {
+	r := &HashRing[T]{
+		replicas: DefaultHashRingReplicas,
+		hashFunc: func(data []byte) uint64 {
+			return uint64(CRC32(data))
+		},
+		weights: make(map[T]int),
+	}
+	if opts != nil {
+		if opts.Replicas > 0 {
+			r.replicas = opts.Replicas
+		}
+		if opts.HashFunc != nil {
+			r.hashFunc = opts.HashFunc
+		}
+	}
+	return r
+}
+
+// Add 添加节点，weight 为节点权重，默认为1，虚拟节点数与权重成正比
+// 节点已存在时更新其权重
+func (r *HashRing[T]) Add(node T, weight ...int) {
+	w := 1
+	if len(weight) > 0 && weight[0] > 0 {
+		w = weight[0]
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights[node] = w
+	r.rebuild()
+}
+
+// Remove 删除节点，节点不存在时返回 false
+func (r *HashRing[T]) Remove(node T) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.weights[node]; !ok {
+		return false
+	}
+	delete(r.weights, node)
+	r.rebuild()
+	return true
+}
+
+// Get 返回 key 对应的节点，环为空时第二个返回值为 false
+func (r *HashRing[T]) Get(key string) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var zero T
+	if len(r.points) == 0 {
+		return zero, false
+	}
+	return r.points[r.search(key)].node, true
+}
+
+// GetN 返回 key 对应的 n 个不同节点，第一个与 Get 的结果相同，可用于多副本存储
+// 节点数不足 n 时返回所有节点
+func (r *HashRing[T]) GetN(key string, n int) []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if n > len(r.weights) {
+		n = len(r.weights)
+	}
+	nodes := make([]T, 0, n)
+	if n <= 0 {
+		return nodes
+	}
+	seen := make(map[T]bool, n)
+	for i, start := 0, r.search(key); len(nodes) < n && i < len(r.points); i++ {
+		p := r.points[(start+i)%len(r.points)]
+		if !seen[p.node] {
+			seen[p.node] = true
+			nodes = append(nodes, p.node)
+		}
+	}
+	return nodes
+}
+
+// Nodes 返回所有节点
+func (r *HashRing[T]) Nodes() []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	nodes := make([]T, 0, len(r.weights))
+	for node := range r.weights {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Len 返回节点数
+func (r *HashRing[T]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.weights)
+}
+
+func (r *HashRing[T]) search(key string) int {
+	h := r.hashFunc([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return i
+}
+
+func (r *HashRing[T]) rebuild() {
+	points := make([]hashRingPoint[T], 0, len(r.points))
+	for node, weight := range r.weights {
+		key := fmt.Sprint(node)
+		for i := 0; i < r.replicas*weight; i++ {
+			vkey := key + "#" + strconv.Itoa(i)
+			points = append(points, hashRingPoint[T]{hash: r.hashFunc([]byte(vkey)), key: vkey, node: node})
+		}
+	}
+	// 哈希值相同时按虚拟节点名称排序，保证结果与添加顺序无关
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].key < points[j].key
+	})
+	r.points = points
+}
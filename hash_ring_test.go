@@ -0,0 +1,122 @@
+package xutils
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestHashRing(t *testing.T) {
+	r := NewHashRing[string](nil)
+	_, ok := r.Get("key")
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(r.GetN("key", 3)))
+
+	r.Add("node1")
+	r.Add("node2")
+	r.Add("node3")
+	assert.Equal(t, 3, r.Len())
+	assert.ElementsMatch(t, []string{"node1", "node2", "node3"}, r.Nodes())
+
+	node, ok := r.Get("key")
+	assert.True(t, ok)
+	nodes := r.GetN("key", 2)
+	assert.Equal(t, 2, len(nodes))
+	assert.Equal(t, node, nodes[0])
+	assert.NotEqual(t, nodes[0], nodes[1])
+	assert.ElementsMatch(t, []string{"node1", "node2", "node3"}, r.GetN("key", 5))
+
+	// 结果与添加顺序无关
+	r2 := NewHashRing[string](nil)
+	r2.Add("node3")
+	r2.Add("node1")
+	r2.Add("node2")
+	for i := 0; i < 1000; i++ {
+		a, _ := r.Get(strconv.Itoa(i))
+		b, _ := r2.Get(strconv.Itoa(i))
+		assert.Equal(t, a, b)
+	}
+
+	assert.True(t, r.Remove("node2"))
+	assert.False(t, r.Remove("node2"))
+	for i := 0; i < 1000; i++ {
+		node, _ = r.Get(strconv.Itoa(i))
+		assert.NotEqual(t, "node2", node)
+	}
+}
+
+func TestHashRingMovement(t *testing.T) {
+	const keys = 100000
+	r := NewHashRing[int](nil)
+	for i := 0; i < 10; i++ {
+		r.Add(i)
+	}
+	before := make([]int, keys)
+	for i := range before {
+		before[i], _ = r.Get("key:" + strconv.Itoa(i))
+	}
+
+	// 添加第 11 个节点后，只有约 1/11 的 key 发生迁移，且都迁移到新节点
+	r.Add(10)
+	moved := 0
+	for i := range before {
+		node, _ := r.Get("key:" + strconv.Itoa(i))
+		if node != before[i] {
+			moved++
+			assert.Equal(t, 10, node)
+		}
+	}
+	ratio := float64(moved) / keys
+	assert.InDelta(t, 1.0/11, ratio, 0.04, "moved %.4f", ratio)
+
+	// 删除节点后，只有原属于该节点的 key 发生迁移
+	r.Remove(10)
+	r.Remove(3)
+	for i := range before {
+		node, _ := r.Get("key:" + strconv.Itoa(i))
+		if before[i] != 3 {
+			assert.Equal(t, before[i], node)
+		}
+	}
+}
+
+func TestHashRingWeight(t *testing.T) {
+	r := NewHashRing[string](&HashRingOptions{Replicas: 100, HashFunc: func(data []byte) uint64 {
+		return XXH3Hash64(data, 0)
+	}})
+	r.Add("small")
+	r.Add("big", 3)
+	counts := make(map[string]int)
+	for i := 0; i < 40000; i++ {
+		node, _ := r.Get(fmt.Sprintf("key-%d", i))
+		counts[node]++
+	}
+	ratio := float64(counts["big"]) / float64(counts["small"])
+	assert.InDelta(t, 3.0, ratio, 0.6, "ratio %.2f", ratio)
+}
+
+func TestHashRingConcurrent(t *testing.T) {
+	r := NewHashRing[string](nil)
+	r.Add("a")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Get(strconv.Itoa(j))
+				r.GetN(strconv.Itoa(j), 2)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			node := "n" + strconv.Itoa(i)
+			r.Add(node)
+			r.Remove(node)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, r.Len())
+}
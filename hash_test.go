@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -75,6 +76,66 @@ func TestHash(t *testing.T) {
 			[]byte("d04cef58583daaed8994f8944d546ad5f390e5b1c329b1849d07cb72918131c1"),
 			false,
 		},
+		{
+			"sha3_224",
+			args{SHA3_224, data, false},
+			[]byte("bcc20e97ee7cd372202f64d62f9e2965c2d1bec27765f6941c98539d"),
+			false,
+		},
+		{
+			"sha3_256",
+			args{SHA3_256, data, false},
+			[]byte("4b5ababed01aae17873a14332c844c53eed41aa147e63d0fd151d9f1a3076590"),
+			false,
+		},
+		{
+			"sha3_384",
+			args{SHA3_384, data, false},
+			[]byte("97d98a5f2309b1c946cfc887de114cba42f777b6067ed59cad2cfb062df73749c7afcfee38b80682d10ee65ba6ebe4d4"),
+			false,
+		},
+		{
+			"sha3_512",
+			args{SHA3_512, data, false},
+			[]byte("82ed3d9f2d4ea973159ecca7c4e129cf003c6dc24370b52ac2c9681b717c01a11ea5bc30d767f654a1a90a299a16db412b7a58ed658819df6b071cf33216cad2"),
+			false,
+		},
+		{
+			"shake128",
+			args{SHAKE128, data, false},
+			[]byte("85f3d4eee3d14fd7263b2e83ae1fb13a26099795db5be983eff77a15b9eb4d22"),
+			false,
+		},
+		{
+			"shake256",
+			args{SHAKE256, data, false},
+			[]byte("00e9769f9aa60912b335292cffb2ee725e3e383572501ec8d94b3bbc005935070d9764039ad5768834c00e8c15df6ce7e5ad922aed94e1a9444b98028e8ba7d5"),
+			false,
+		},
+		{
+			"blake2b_256",
+			args{BLAKE2B_256, data, false},
+			[]byte("424a4c78ed70976758f4da4fc95d3c146883da761cb798b6de6ea5d8d9f85c7f"),
+			false,
+		},
+		{
+			"blake2b_384",
+			args{BLAKE2B_384, data, false},
+			[]byte("a9f04282bfc58c77fd56af3d5f8140988626e377f4a28e65363a97fad91c35b3d07bbf5fce8e0a870313dfb133d1e045"),
+			false,
+		},
+		{
+			"blake2b_512",
+			args{BLAKE2B_512, data, false},
+			[]byte("94a2c3bc859bd16e0837566d1d38cca2f5c4089eff693b01dacfacc2e31b7364c35ae298372f5051209588c01ddbdf0e14f8f3b36f087c8ad283ca400310e4d2"),
+			false,
+		},
+		{
+			"blake2s_256",
+			args{BLAKE2S_256, data, false},
+			[]byte("12480513e18e87d77996db3a4c0f75ae5e16bf13ea0ecb651818f78d9b7475ef"),
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -106,3 +167,175 @@ func TestHashFile(t *testing.T) {
 	}
 	t.Log(string(h))
 }
+
+func TestHmac(t *testing.T) {
+	// RFC 4231 测试用例2
+	key := []byte("Jefe")
+	msg := []byte("what do ya want for nothing?")
+	tests := []struct {
+		algo HashAlgo
+		want string
+	}{
+		{MD5, "750c783e6ab0b503eaa86e310a5db738"},
+		{SHA1, "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79"},
+		{SHA224, "a30e01098bc6dbbf45690f3a7e9e6d0f8bbea2a39e6148008fd05e44"},
+		{SHA256, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{SHA384, "af45d2e376484031617f78d2b58a6b1b9c7ef464f5a01b47e42ec3736322445e8e2240ca5e69e2c78b3239ecfab21649"},
+		{SHA512, "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"},
+	}
+	for _, tt := range tests {
+		got, err := Hmac(tt.algo, key, msg, false)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, string(got))
+
+		s, err := HmacString(tt.algo, string(key), string(msg), false)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, s)
+
+		raw, err := Hmac(tt.algo, key, msg, true)
+		assert.Nil(t, err)
+		assert.Equal(t, len(tt.want)/2, len(raw))
+	}
+	_, err := Hmac(HashAlgo(99), key, msg, false)
+	assert.NotNil(t, err)
+
+	// 非密码学算法不能用于 HMAC
+	for _, algo := range []HashAlgo{CRC32_IEEE, CRC64_ECMA, ADLER32, FNV1A_64, FNV1_128, XXH64, XXH3_64, MURMUR3_32, MURMUR3_128} {
+		_, err = Hmac(algo, key, msg, false)
+		assert.NotNil(t, err, algo.String())
+	}
+	for _, algo := range []HashAlgo{SHA3_256, SHAKE256, BLAKE2B_512, BLAKE2S_256} {
+		_, err = Hmac(algo, key, msg, false)
+		assert.Nil(t, err, algo.String())
+	}
+}
+
+func TestHmacFile(t *testing.T) {
+	f, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := f.Name()
+	f.Write(data)
+	f.Close()
+	defer os.Remove(fn)
+
+	h, err := HmacFile(SHA256, []byte("key"), fn, false)
+	assert.Nil(t, err)
+	h2, _ := Hmac(SHA256, []byte("key"), data, false)
+	assert.Equal(t, h2, h)
+
+	_, err = HmacFile(SHA256, []byte("key"), fn+".not_exists", false)
+	assert.NotNil(t, err)
+}
+
+func TestHmacVerify(t *testing.T) {
+	key := []byte("Jefe")
+	msg := []byte("what do ya want for nothing?")
+	hexMac := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	raw, _ := Hmac(SHA256, key, msg, true)
+
+	assert.True(t, HmacVerify(SHA256, key, msg, []byte(hexMac)))
+	assert.True(t, HmacVerify(SHA256, key, msg, []byte(strings.ToUpper(hexMac))))
+	assert.True(t, HmacVerify(SHA256, key, msg, raw))
+	assert.False(t, HmacVerify(SHA256, key, msg, raw[:31]))
+	assert.False(t, HmacVerify(SHA256, key, msg, []byte("zz"+hexMac[2:])))
+	assert.False(t, HmacVerify(SHA256, []byte("key"), msg, raw))
+	assert.False(t, HmacVerify(HashAlgo(99), key, msg, raw))
+
+	// GitHub Webhook 签名格式
+	assert.True(t, HmacVerify(SHA256, key, msg, []byte("sha256="+hexMac)))
+	assert.True(t, HmacVerify(SHA256, key, msg, []byte("SHA256="+hexMac)))
+	assert.False(t, HmacVerify(SHA256, key, msg, []byte("sha1="+hexMac)))
+	assert.False(t, HmacVerify(SHA256, key, msg, []byte("sha256="+hexMac[:62])))
+	sha1Mac, _ := Hmac(SHA1, key, msg, false)
+	assert.True(t, HmacVerify(SHA1, key, msg, append([]byte("sha1="), sha1Mac...)))
+
+	// 非密码学算法
+	assert.False(t, HmacVerify(ADLER32, key, msg, []byte("095e01fd")))
+	assert.False(t, HmacVerify(CRC32_IEEE, key, msg, raw[:4]))
+}
+
+func TestHashChecksum(t *testing.T) {
+	// 各算法对 "123456789" 的标准校验值
+	tests := []struct {
+		algo HashAlgo
+		want string
+	}{
+		{CRC32_IEEE, "cbf43926"},
+		{CRC32_CASTAGNOLI, "e3069283"},
+		{CRC32_KOOPMAN, "2d3dd0ae"},
+		{CRC64_ISO, "b90956c775a41001"},
+		{CRC64_ECMA, "995dc9bbdf1939fa"},
+		{ADLER32, "091e01de"},
+		{FNV1_32, "24148816"},
+		{FNV1A_32, "bb86b11c"},
+		{FNV1_64, "a72ffc362bf916d6"},
+		{FNV1A_64, "06d5573923c6cdfc"},
+		{FNV1_128, "8bea2c73be03b30fd4142fb1ec2c2066"},
+		{FNV1A_128, "da2d42a08d04e4585dd325117f71d504"},
+	}
+	for _, tt := range tests {
+		got, err := HashString(tt.algo, "123456789", false)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, got, tt.algo.String())
+	}
+}
+
+func TestParseHashAlgo(t *testing.T) {
+	tests := map[string]HashAlgo{
+		"md5":              MD5,
+		"SHA-1":            SHA1,
+		"sha256":           SHA256,
+		"SHA512/256":       SHA512_256,
+		"sha512_224":       SHA512_224,
+		"sha3-256":         SHA3_256,
+		"SHA3_512":         SHA3_512,
+		"shake128":         SHAKE128,
+		"blake2b":          BLAKE2B_512,
+		"BLAKE2b-256":      BLAKE2B_256,
+		"blake2s":          BLAKE2S_256,
+		"crc32":            CRC32_IEEE,
+		"crc32c":           CRC32_CASTAGNOLI,
+		"crc32-castagnoli": CRC32_CASTAGNOLI,
+		"crc32-koopman":    CRC32_KOOPMAN,
+		"crc64-ecma":       CRC64_ECMA,
+		"adler32":          ADLER32,
+		"fnv1a-64":         FNV1A_64,
+		"fnv32":            FNV1_32,
+	}
+	for name, want := range tests {
+		got, err := ParseHashAlgo(name)
+		assert.Nil(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := ParseHashAlgo("sha")
+	assert.NotNil(t, err)
+
+	// 所有算法都可以通过名称解析，并且名称不重复
+	seen := make(map[string]HashAlgo)
+	for algo, names := range hashNames {
+		_, ok := hashes[algo]
+		assert.True(t, ok, algo.String())
+		for _, name := range names {
+			key := normalizeHashName(name)
+			_, dup := seen[key]
+			assert.False(t, dup, name)
+			seen[key] = algo
+		}
+		got, err := ParseHashAlgo(algo.String())
+		assert.Nil(t, err)
+		assert.Equal(t, algo, got)
+	}
+	assert.Equal(t, len(hashes), len(hashNames))
+	assert.Equal(t, "HashAlgo(99)", HashAlgo(99).String())
+}
+
+func TestHashSize(t *testing.T) {
+	for algo := range hashes {
+		h, _ := newHash(algo)
+		sum, err := Hash(algo, data, true)
+		assert.Nil(t, err)
+		assert.Equal(t, h.Size(), len(sum), algo.String())
+	}
+}
@@ -0,0 +1,375 @@
+package xutils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWTAlgo JWT签名算法，即JWT头部中的 alg
+type JWTAlgo string
+
+const (
+	HS256 JWTAlgo = "HS256"
+	HS384 JWTAlgo = "HS384"
+	HS512 JWTAlgo = "HS512"
+	RS256 JWTAlgo = "RS256"
+	RS384 JWTAlgo = "RS384"
+	RS512 JWTAlgo = "RS512"
+	PS256 JWTAlgo = "PS256"
+	PS384 JWTAlgo = "PS384"
+	PS512 JWTAlgo = "PS512"
+	ES256 JWTAlgo = "ES256"
+	ES384 JWTAlgo = "ES384"
+	ES512 JWTAlgo = "ES512"
+	EdDSA JWTAlgo = "EdDSA"
+)
+
+var (
+	ErrJWTMalformed        = errors.New("jwt: malformed token")
+	ErrJWTAlgNotAllowed    = errors.New("jwt: algorithm not allowed")
+	ErrJWTInvalidKey       = errors.New("jwt: invalid key for algorithm")
+	ErrJWTSignatureInvalid = errors.New("jwt: signature is invalid")
+	ErrJWTExpired          = errors.New("jwt: token is expired")
+	ErrJWTNotValidYet      = errors.New("jwt: token is not valid yet")
+	ErrJWTIssuedInFuture   = errors.New("jwt: token used before issued")
+	ErrJWTMissingExp       = errors.New("jwt: exp claim is required")
+	ErrJWTInvalidIssuer    = errors.New("jwt: invalid issuer")
+	ErrJWTInvalidAudience  = errors.New("jwt: invalid audience")
+)
+
+// JWTHeader JWT头部
+type JWTHeader struct {
+	Alg JWTAlgo `json:"alg"`
+	Typ string  `json:"typ,omitempty"`
+	Kid string  `json:"kid,omitempty"`
+}
+
+// JWTClaims JWT标准声明，自定义声明的结构体可以嵌入 JWTClaims
+type JWTClaims struct {
+	Issuer    string         `json:"iss,omitempty"`
+	Subject   string         `json:"sub,omitempty"`
+	Audience  JWTAudience    `json:"aud,omitempty"`
+	ExpiresAt JWTNumericDate `json:"exp,omitempty"`
+	NotBefore JWTNumericDate `json:"nbf,omitempty"`
+	IssuedAt  JWTNumericDate `json:"iat,omitempty"`
+	ID        string         `json:"jti,omitempty"`
+}
+
+// JWTAudience aud 声明，可以是单个字符串或字符串数组
+type JWTAudience []string
+
+// JWTNumericDate unix时间戳（秒），0表示未设置
+type JWTNumericDate int64
+
+// JWTKeyFunc 根据JWT头部（如 kid）返回校验签名使用的公钥或HMAC密钥
+type JWTKeyFunc func(header JWTHeader) (any, error)
+
+// JWTOptions JWTParse 的校验选项
+type JWTOptions struct {
+	// Algorithms 允许的签名算法，必须设置，不在列表中的算法直接拒绝
+	Algorithms []JWTAlgo
+	// Leeway 校验 exp/nbf/iat 时允许的时钟偏差
+	Leeway time.Duration
+	// Issuer 不为空时要求 iss 与之相等
+	Issuer string
+	// Audience 不为空时要求 aud 中包含该值
+	Audience string
+	// RequireExp 是否要求必须包含 exp 声明
+	RequireExp bool
+	// Now 返回当前时间，为空时使用 time.Now
+	Now func() time.Time
+}
+
+// NewJWTNumericDate 将时间转换为 JWTNumericDate
+func NewJWTNumericDate(t time.Time) JWTNumericDate {
+	return JWTNumericDate(t.Unix())
+}
+
+// Time 返回对应的时间
+func (d JWTNumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// UnmarshalJSON 兼容浮点数格式的时间戳
+func (d *JWTNumericDate) UnmarshalJSON(b []byte) error {
+	var f float64
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	if f < math.MinInt64 || f > math.MaxInt64 {
+		return errors.New("numeric date out of range")
+	}
+	*d = JWTNumericDate(f)
+	return nil
+}
+
+// MarshalJSON 只有一个值时编码为字符串
+func (a JWTAudience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON 兼容字符串和字符串数组
+func (a *JWTAudience) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		var ss []string
+		if err := json.Unmarshal(b, &ss); err != nil {
+			return err
+		}
+		*a = ss
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*a = JWTAudience{s}
+	return nil
+}
+
+// JWTSign 生成JWT，claims 为任意可以JSON编码的值，通常为嵌入了 JWTClaims 的结构体
+// HS* 算法的 key 为 []byte；RS*/PS* 为 *rsa.PrivateKey；ES* 为对应曲线的 *ecdsa.PrivateKey；
+// EdDSA 为 ed25519.PrivateKey
+func JWTSign(claims any, key any, alg JWTAlgo) (string, error) {
+	return JWTSignWithHeader(JWTHeader{Alg: alg}, claims, key)
+}
+
+// JWTSignWithHeader 使用指定的头部生成JWT，可以用于设置 kid
+func JWTSignWithHeader(header JWTHeader, claims any, key any) (string, error) {
+	if header.Typ == "" {
+		header.Typ = "JWT"
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := jwtSign(header.Alg, []byte(signingInput), key)
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// JWTParse 校验并解析JWT，返回 T 类型的声明
+// 依次校验算法是否在 opts.Algorithms 中、签名、exp/nbf/iat、iss 和 aud；
+// keyFunc 返回的密钥类型必须与算法匹配，防止算法混淆攻击（如使用RSA公钥作为HMAC密钥）
+func JWTParse[T any](token string, keyFunc JWTKeyFunc, opts JWTOptions) (T, error) {
+	var claims T
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrJWTMalformed
+	}
+	var header JWTHeader
+	if err := jwtDecodeSegment(parts[0], &header); err != nil {
+		return claims, err
+	}
+	if !InArray(header.Alg, opts.Algorithms) || strings.EqualFold(string(header.Alg), "none") {
+		return claims, ErrJWTAlgNotAllowed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, ErrJWTMalformed
+	}
+	key, err := keyFunc(header)
+	if err != nil {
+		return claims, err
+	}
+	signingInput := token[:len(parts[0])+1+len(parts[1])]
+	if err = jwtVerify(header.Alg, []byte(signingInput), sig, key); err != nil {
+		return claims, err
+	}
+
+	var registered JWTClaims
+	if err = jwtDecodeSegment(parts[1], &registered); err != nil {
+		return claims, err
+	}
+	if err = registered.validate(opts); err != nil {
+		return claims, err
+	}
+	if err = jwtDecodeSegment(parts[1], &claims); err != nil {
+		return claims, err
+	}
+	return claims, nil
+}
+
+func (c *JWTClaims) validate(opts JWTOptions) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	if c.ExpiresAt == 0 && opts.RequireExp {
+		return ErrJWTMissingExp
+	}
+	if c.ExpiresAt != 0 && !now.Before(c.ExpiresAt.Time().Add(opts.Leeway)) {
+		return ErrJWTExpired
+	}
+	if c.NotBefore != 0 && now.Add(opts.Leeway).Before(c.NotBefore.Time()) {
+		return ErrJWTNotValidYet
+	}
+	if c.IssuedAt != 0 && now.Add(opts.Leeway).Before(c.IssuedAt.Time()) {
+		return ErrJWTIssuedInFuture
+	}
+	if opts.Issuer != "" && c.Issuer != opts.Issuer {
+		return ErrJWTInvalidIssuer
+	}
+	if opts.Audience != "" && !InArray(opts.Audience, c.Audience) {
+		return ErrJWTInvalidAudience
+	}
+	return nil
+}
+
+func jwtDecodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return ErrJWTMalformed
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return ErrJWTMalformed
+	}
+	return nil
+}
+
+// jwtHashAlgo 返回算法使用的摘要算法
+func jwtHashAlgo(alg JWTAlgo) (HashAlgo, crypto.Hash) {
+	switch alg[len(alg)-3:] {
+	case "256":
+		return SHA256, crypto.SHA256
+	case "384":
+		return SHA384, crypto.SHA384
+	}
+	return SHA512, crypto.SHA512
+}
+
+// jwtCurve 返回ES算法对应的曲线
+func jwtCurve(alg JWTAlgo) elliptic.Curve {
+	switch alg {
+	case ES256:
+		return elliptic.P256()
+	case ES384:
+		return elliptic.P384()
+	}
+	return elliptic.P521()
+}
+
+func jwtSign(alg JWTAlgo, data []byte, key any) ([]byte, error) {
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return nil, ErrJWTInvalidKey
+		}
+		algo, _ := jwtHashAlgo(alg)
+		h := hmac.New(hashes[algo], secret)
+		h.Write(data)
+		return h.Sum(nil), nil
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrJWTInvalidKey
+		}
+		algo, hash := jwtHashAlgo(alg)
+		digest, _ := Hash(algo, data, true)
+		if alg[0] == 'P' {
+			return rsa.SignPSS(rand.Reader, priv, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, hash, digest)
+	case ES256, ES384, ES512:
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != jwtCurve(alg) {
+			return nil, ErrJWTInvalidKey
+		}
+		algo, _ := jwtHashAlgo(alg)
+		digest, _ := Hash(algo, data, true)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest)
+		if err != nil {
+			return nil, err
+		}
+		// 签名为定长的 R || S，而不是ASN.1编码
+		size := (priv.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, size*2)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	case EdDSA:
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok || len(priv) != ed25519.PrivateKeySize {
+			return nil, ErrJWTInvalidKey
+		}
+		return Sign(priv, data)
+	}
+	return nil, ErrJWTAlgNotAllowed
+}
+
+func jwtVerify(alg JWTAlgo, data []byte, sig []byte, key any) error {
+	valid := false
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return ErrJWTInvalidKey
+		}
+		expected, err := jwtSign(alg, data, secret)
+		if err != nil {
+			return err
+		}
+		valid = hmac.Equal(sig, expected)
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrJWTInvalidKey
+		}
+		algo, hash := jwtHashAlgo(alg)
+		digest, _ := Hash(algo, data, true)
+		if alg[0] == 'P' {
+			valid = rsa.VerifyPSS(pub, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil
+		} else {
+			valid = rsa.VerifyPKCS1v15(pub, hash, digest, sig) == nil
+		}
+	case ES256, ES384, ES512:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != jwtCurve(alg) {
+			return ErrJWTInvalidKey
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != size*2 {
+			return ErrJWTSignatureInvalid
+		}
+		algo, _ := jwtHashAlgo(alg)
+		digest, _ := Hash(algo, data, true)
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		valid = ecdsa.Verify(pub, digest, r, s)
+	case EdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrJWTInvalidKey
+		}
+		valid = Verify(pub, data, sig) == nil
+	default:
+		return ErrJWTAlgNotAllowed
+	}
+	if !valid {
+		return ErrJWTSignatureInvalid
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package xutils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTPOptions 一次性密码参数，为0的字段使用默认值
+type OTPOptions struct {
+	// Algo HMAC使用的哈希算法，支持 SHA1、SHA256、SHA512，默认 SHA1
+	Algo HashAlgo
+	// Digits 密码位数，6-8位，默认6位
+	Digits int
+	// Period TOTP的时间步长，默认30秒
+	Period time.Duration
+	// Window 校验时允许的偏差，TOTP为前后各 Window 个时间步长，HOTP为向后 Window 个计数，
+	// 默认1，设置为负数表示不允许偏差
+	Window int
+}
+
+var otpAlgoNames = map[HashAlgo]string{
+	SHA1:   "SHA1",
+	SHA256: "SHA256",
+	SHA512: "SHA512",
+}
+
+// GenerateOTPSecret 生成 size 字节的随机密钥，返回base32编码（无填充）的字符串，size为0时使用20字节
+func GenerateOTPSecret(size int) (string, error) {
+	if size <= 0 {
+		size = 20
+	}
+	secret := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// DecodeOTPSecret 解码base32格式的密钥，忽略大小写、空格和填充
+func DecodeOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
+
+// HOTP 根据计数器生成一次性密码（RFC 4226）
+func HOTP(secret []byte, counter uint64, opts *OTPOptions) (string, error) {
+	o, err := otpOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	return hotp(secret, counter, o), nil
+}
+
+// HOTPVerify 校验HOTP密码，从 counter 开始向后尝试 Window 个计数
+// 校验成功时返回下一次使用的计数器值，调用方需要保存该值
+func HOTPVerify(code string, secret []byte, counter uint64, opts *OTPOptions) (uint64, bool) {
+	o, err := otpOptions(opts)
+	if err != nil || len(code) != o.Digits {
+		return counter, false
+	}
+	for i := uint64(0); i <= uint64(o.Window); i++ {
+		if subtle.ConstantTimeCompare([]byte(code), []byte(hotp(secret, counter+i, o))) == 1 {
+			return counter + i + 1, true
+		}
+	}
+	return counter, false
+}
+
+// TOTP 根据时间生成一次性密码（RFC 6238）
+func TOTP(secret []byte, t time.Time, opts *OTPOptions) (string, error) {
+	o, err := otpOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	return hotp(secret, totpStep(t, o), o), nil
+}
+
+// TOTPVerify 校验TOTP密码，允许前后 Window 个时间步长的偏差
+// lastStep 为该密钥上一次校验成功时返回的时间步，不大于 lastStep 的密码会被拒绝，防止同一个密码被重复使用；
+// 首次校验时传0。校验成功时返回匹配的时间步，调用方需要保存该值
+func TOTPVerify(code string, secret []byte, t time.Time, lastStep uint64, opts *OTPOptions) (uint64, bool) {
+	o, err := otpOptions(opts)
+	if err != nil || len(code) != o.Digits {
+		return lastStep, false
+	}
+	step := totpStep(t, o)
+	for i := -o.Window; i <= o.Window; i++ {
+		s := step + uint64(i)
+		if (i < 0 && step < uint64(-i)) || s <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(hotp(secret, s, o))) == 1 {
+			return s, true
+		}
+	}
+	return lastStep, false
+}
+
+// TOTPURI 生成 otpauth://totp/ 格式的URI，可以生成二维码供身份验证器App扫描
+func TOTPURI(secret []byte, issuer string, account string, opts *OTPOptions) (string, error) {
+	o, err := otpOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	params := otpURIParams(secret, issuer, o)
+	params.Set("period", strconv.Itoa(int(o.Period/time.Second)))
+	return otpURI("totp", issuer, account, params), nil
+}
+
+// HOTPURI 生成 otpauth://hotp/ 格式的URI，counter 为初始计数器
+func HOTPURI(secret []byte, issuer string, account string, counter uint64, opts *OTPOptions) (string, error) {
+	o, err := otpOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	params := otpURIParams(secret, issuer, o)
+	params.Set("counter", strconv.FormatUint(counter, 10))
+	return otpURI("hotp", issuer, account, params), nil
+}
+
+func otpURIParams(secret []byte, issuer string, o OTPOptions) url.Values {
+	params := url.Values{}
+	params.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret))
+	if issuer != "" {
+		params.Set("issuer", issuer)
+	}
+	params.Set("algorithm", otpAlgoNames[o.Algo])
+	params.Set("digits", strconv.Itoa(o.Digits))
+	return params
+}
+
+func otpURI(typ string, issuer string, account string, params url.Values) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+	return fmt.Sprintf("otpauth://%s/%s?%s", typ, label, strings.ReplaceAll(params.Encode(), "+", "%20"))
+}
+
+func otpOptions(opts *OTPOptions) (OTPOptions, error) {
+	o := OTPOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Algo == 0 {
+		o.Algo = SHA1
+	}
+	if o.Digits == 0 {
+		o.Digits = 6
+	}
+	if o.Period == 0 {
+		o.Period = 30 * time.Second
+	}
+	if o.Window == 0 {
+		o.Window = 1
+	} else if o.Window < 0 {
+		o.Window = 0
+	}
+	if _, ok := otpAlgoNames[o.Algo]; !ok {
+		return o, errors.New("unsupported otp hash algorithm")
+	}
+	if o.Digits < 6 || o.Digits > 8 {
+		return o, errors.New("otp digits must be between 6 and 8")
+	}
+	if o.Period < time.Second {
+		return o, errors.New("invalid otp options")
+	}
+	return o, nil
+}
+
+func totpStep(t time.Time, o OTPOptions) uint64 {
+	return uint64(t.Unix()) / uint64(o.Period/time.Second)
+}
+
+func hotp(secret []byte, counter uint64, o OTPOptions) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	h := hmac.New(hashes[o.Algo], secret)
+	h.Write(msg)
+	sum := h.Sum(nil)
+
+	// 动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < o.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", o.Digits, value%mod)
+}
@@ -0,0 +1,115 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 附录D
+	secret := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for i, code := range want {
+		got, err := HOTP(secret, uint64(i), nil)
+		assert.Nil(t, err)
+		assert.Equal(t, code, got)
+	}
+
+	next, ok := HOTPVerify("359152", secret, 1, nil)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), next)
+	_, ok = HOTPVerify("969429", secret, 1, nil)
+	assert.False(t, ok)
+	_, ok = HOTPVerify("359152", secret, 2, &OTPOptions{Window: -1})
+	assert.True(t, ok)
+	_, ok = HOTPVerify("359152", secret, 1, &OTPOptions{Window: -1})
+	assert.False(t, ok)
+	_, ok = HOTPVerify("35915", secret, 2, nil)
+	assert.False(t, ok)
+}
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 附录B
+	secrets := map[HashAlgo][]byte{
+		SHA1:   []byte("12345678901234567890"),
+		SHA256: []byte("12345678901234567890123456789012"),
+		SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	tests := []struct {
+		ts   int64
+		want map[HashAlgo]string
+	}{
+		{59, map[HashAlgo]string{SHA1: "94287082", SHA256: "46119246", SHA512: "90693936"}},
+		{1111111109, map[HashAlgo]string{SHA1: "07081804", SHA256: "68084774", SHA512: "25091201"}},
+		{1111111111, map[HashAlgo]string{SHA1: "14050471", SHA256: "67062674", SHA512: "99943326"}},
+		{1234567890, map[HashAlgo]string{SHA1: "89005924", SHA256: "91819424", SHA512: "93441116"}},
+		{2000000000, map[HashAlgo]string{SHA1: "69279037", SHA256: "90698825", SHA512: "38618901"}},
+		{20000000000, map[HashAlgo]string{SHA1: "65353130", SHA256: "77737706", SHA512: "47863826"}},
+	}
+	for _, tt := range tests {
+		for algo, want := range tt.want {
+			got, err := TOTP(secrets[algo], time.Unix(tt.ts, 0), &OTPOptions{Algo: algo, Digits: 8})
+			assert.Nil(t, err)
+			assert.Equal(t, want, got, "%d %d", tt.ts, algo)
+		}
+	}
+
+	_, err := TOTP(secrets[SHA1], time.Now(), &OTPOptions{Algo: MD5})
+	assert.NotNil(t, err)
+	_, err = TOTP(secrets[SHA1], time.Now(), &OTPOptions{Digits: 10})
+	assert.NotNil(t, err)
+}
+
+func TestTOTPVerify(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	code, _ := TOTP(secret, now, nil)
+
+	step, ok := TOTPVerify(code, secret, now, 0, nil)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1111111111/30), step)
+
+	// 同一个时间步内不能重复使用
+	_, ok = TOTPVerify(code, secret, now, step, nil)
+	assert.False(t, ok)
+
+	// 时间偏差
+	_, ok = TOTPVerify(code, secret, now.Add(30*time.Second), 0, nil)
+	assert.True(t, ok)
+	_, ok = TOTPVerify(code, secret, now.Add(90*time.Second), 0, nil)
+	assert.False(t, ok)
+	_, ok = TOTPVerify(code, secret, now.Add(90*time.Second), 0, &OTPOptions{Window: 3})
+	assert.True(t, ok)
+	_, ok = TOTPVerify(code, secret, now.Add(30*time.Second), 0, &OTPOptions{Window: -1})
+	assert.False(t, ok)
+	_, ok = TOTPVerify("000000", secret, now, 0, nil)
+	assert.False(t, ok)
+}
+
+func TestOTPSecret(t *testing.T) {
+	s, err := GenerateOTPSecret(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 32, len(s))
+	secret, err := DecodeOTPSecret(strings.ToLower(s))
+	assert.Nil(t, err)
+	assert.Equal(t, 20, len(secret))
+
+	secret, err = DecodeOTPSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	assert.Nil(t, err)
+	assert.Equal(t, "12345678901234567890", string(secret))
+	_, err = DecodeOTPSecret("189!")
+	assert.NotNil(t, err)
+}
+
+func TestOTPURI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	uri, err := TOTPURI(secret, "Example Co", "alice@example.com", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "otpauth://totp/Example%20Co:alice@example.com?algorithm=SHA1&digits=6&issuer=Example%20Co&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri)
+
+	uri, err = HOTPURI(secret, "", "bob", 5, &OTPOptions{Algo: SHA256, Digits: 8})
+	assert.Nil(t, err)
+	assert.Equal(t, "otpauth://hotp/bob?algorithm=SHA256&counter=5&digits=8&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri)
+}
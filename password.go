@@ -0,0 +1,214 @@
+package xutils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"strings"
+)
+
+// PasswordAlgo 密码哈希算法，取值与php的 PASSWORD_* 常量一致
+type PasswordAlgo string
+
+const (
+	PasswordBcrypt   PasswordAlgo = "2y"
+	PasswordArgon2i  PasswordAlgo = "argon2i"
+	PasswordArgon2id PasswordAlgo = "argon2id"
+	PasswordDefault               = PasswordBcrypt
+)
+
+// PasswordOptions 密码哈希参数，为0的字段使用与php相同的默认值
+type PasswordOptions struct {
+	// Cost bcrypt 的计算成本，默认10
+	Cost int
+	// MemoryCost argon2 使用的内存（KiB），默认65536
+	MemoryCost uint32
+	// TimeCost argon2 的迭代次数，默认4
+	TimeCost uint32
+	// Threads argon2 的并行线程数，默认1
+	Threads uint8
+}
+
+const (
+	bcryptMaxPasswordLen = 72
+	argon2SaltLen        = 16
+	argon2KeyLen         = 32
+	argon2MaxKeyLen      = 128
+)
+
+// 校验 argon2 哈希时允许的最大参数。参数来自保存的哈希字符串，argon2 会按 m 一次性分配内存，
+// 不加限制时被篡改或构造的哈希可以让进程因内存不足直接退出。默认为 1GiB 内存、16 次迭代，
+// 使用更高参数时可以调大，PasswordHash 也不会生成超过限制的哈希
+var (
+	MaxArgon2Memory uint32 = 1 << 20
+	MaxArgon2Time   uint32 = 16
+)
+
+// PasswordHash 创建密码的哈希，类似php的 password_hash 函数
+// bcrypt 生成 $2y$ 格式，argon2 生成 $argon2id$v=19$m=65536,t=4,p=1$salt$hash 格式，
+// 结果可以直接被php的 password_verify 校验
+// 与php一致，bcrypt 只使用密码的前72个字节
+func PasswordHash(password string, algo PasswordAlgo, opts *PasswordOptions) (string, error) {
+	o := passwordOptions(opts)
+	switch algo {
+	case PasswordBcrypt:
+		if o.Cost < bcrypt.MinCost || o.Cost > bcrypt.MaxCost {
+			return "", fmt.Errorf("invalid bcrypt cost parameter: %d", o.Cost)
+		}
+		h, err := bcrypt.GenerateFromPassword(bcryptPassword(password), o.Cost)
+		if err != nil {
+			return "", err
+		}
+		// golang.org/x/crypto/bcrypt 生成的是 $2a$ 前缀，算法与 $2y$ 相同
+		return "$2y$" + string(h[4:]), nil
+	case PasswordArgon2i, PasswordArgon2id:
+		if o.MemoryCost < 8*uint32(o.Threads) || o.TimeCost < 1 || o.Threads < 1 {
+			return "", errors.New("invalid argon2 parameters")
+		}
+		if err := checkArgon2Limits(o); err != nil {
+			return "", err
+		}
+		salt := make([]byte, argon2SaltLen)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return "", err
+		}
+		key := argon2Key(algo, []byte(password), salt, o, argon2KeyLen)
+		return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", algo, argon2.Version, o.MemoryCost, o.TimeCost, o.Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	return "", errors.New("unknown password algorithm")
+}
+
+// PasswordVerify 校验密码与哈希是否匹配，类似php的 password_verify 函数
+// 支持 $2y$/$2a$/$2b$ 格式的 bcrypt 哈希和 $argon2i$/$argon2id$ 格式的哈希
+func PasswordVerify(password string, hash string) bool {
+	info, err := parsePasswordHash(hash)
+	if err != nil {
+		return false
+	}
+	switch info.algo {
+	case PasswordBcrypt:
+		return bcrypt.CompareHashAndPassword([]byte(hash), bcryptPassword(password)) == nil
+	case PasswordArgon2i, PasswordArgon2id:
+		key := argon2Key(info.algo, []byte(password), info.salt, info.opts, uint32(len(info.key)))
+		return subtle.ConstantTimeCompare(key, info.key) == 1
+	}
+	return false
+}
+
+// PasswordNeedsRehash 检查哈希是否与指定的算法和参数一致，类似php的 password_needs_rehash 函数
+// 可以在用户登录校验密码成功后调用，返回true时使用 PasswordHash 重新生成哈希并保存
+func PasswordNeedsRehash(hash string, algo PasswordAlgo, opts *PasswordOptions) bool {
+	info, err := parsePasswordHash(hash)
+	if err != nil || info.algo != algo {
+		return true
+	}
+	o := passwordOptions(opts)
+	switch algo {
+	case PasswordBcrypt:
+		return info.opts.Cost != o.Cost
+	case PasswordArgon2i, PasswordArgon2id:
+		return info.opts.MemoryCost != o.MemoryCost || info.opts.TimeCost != o.TimeCost || info.opts.Threads != o.Threads
+	}
+	return true
+}
+
+type passwordHashInfo struct {
+	algo PasswordAlgo
+	opts PasswordOptions
+	salt []byte
+	key  []byte
+}
+
+func parsePasswordHash(hash string) (*passwordHashInfo, error) {
+	if strings.HasPrefix(hash, "$2y$") || strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") {
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return nil, err
+		}
+		return &passwordHashInfo{algo: PasswordBcrypt, opts: PasswordOptions{Cost: cost}}, nil
+	}
+
+	// $argon2id$v=19$m=65536,t=4,p=1$salt$hash
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return nil, errors.New("unknown password hash format")
+	}
+	info := &passwordHashInfo{algo: PasswordAlgo(parts[1])}
+	if info.algo != PasswordArgon2i && info.algo != PasswordArgon2id {
+		return nil, errors.New("unknown password hash format")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errors.New("unsupported argon2 version")
+	}
+	o := &info.opts
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &o.MemoryCost, &o.TimeCost, &o.Threads); err != nil {
+		return nil, errors.New("invalid argon2 parameters")
+	}
+	if o.TimeCost < 1 || o.Threads < 1 {
+		return nil, errors.New("invalid argon2 parameters")
+	}
+	if err := checkArgon2Limits(*o); err != nil {
+		return nil, err
+	}
+	var err error
+	if info.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if info.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	if len(info.key) == 0 || len(info.key) > argon2MaxKeyLen {
+		return nil, errors.New("invalid argon2 hash")
+	}
+	return info, nil
+}
+
+// checkArgon2Limits 检查 argon2 参数是否超过 MaxArgon2Memory、MaxArgon2Time 的限制
+func checkArgon2Limits(o PasswordOptions) error {
+	if o.MemoryCost > MaxArgon2Memory || o.TimeCost > MaxArgon2Time {
+		return errors.New("argon2 parameters exceed limit")
+	}
+	return nil
+}
+
+func passwordOptions(opts *PasswordOptions) PasswordOptions {
+	o := PasswordOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Cost == 0 {
+		o.Cost = bcrypt.DefaultCost
+	}
+	if o.MemoryCost == 0 {
+		o.MemoryCost = 65536
+	}
+	if o.TimeCost == 0 {
+		o.TimeCost = 4
+	}
+	if o.Threads == 0 {
+		o.Threads = 1
+	}
+	return o
+}
+
+func argon2Key(algo PasswordAlgo, password, salt []byte, o PasswordOptions, keyLen uint32) []byte {
+	if algo == PasswordArgon2i {
+		return argon2.Key(password, salt, o.TimeCost, o.MemoryCost, o.Threads, keyLen)
+	}
+	return argon2.IDKey(password, salt, o.TimeCost, o.MemoryCost, o.Threads, keyLen)
+}
+
+// bcryptPassword bcrypt 只使用前72个字节，与php保持一致，超出部分直接截断
+func bcryptPassword(password string) []byte {
+	if len(password) > bcryptMaxPasswordLen {
+		password = password[:bcryptMaxPasswordLen]
+	}
+	return []byte(password)
+}
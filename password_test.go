@@ -0,0 +1,87 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasswordVerifyPHP(t *testing.T) {
+	// php 文档中的示例
+	assert.True(t, PasswordVerify("rasmuslerdorf", "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a"))
+	assert.False(t, PasswordVerify("rasmuslerdorF", "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a"))
+	assert.True(t, PasswordVerify("rasmuslerdorf", "$argon2i$v=19$m=1024,t=2,p=2$YzJBSzV4TUhkMzc3d3laeg$zqU/1IN0/AogfP4cmSJI1vc8lpXRW9/S0sYY2i2jHT0"))
+	assert.False(t, PasswordVerify("rasmuslerdorF", "$argon2i$v=19$m=1024,t=2,p=2$YzJBSzV4TUhkMzc3d3laeg$zqU/1IN0/AogfP4cmSJI1vc8lpXRW9/S0sYY2i2jHT0"))
+
+	for _, h := range []string{"", "plain", "$1$abc$def", "$argon2id$v=16$m=1024,t=2,p=2$YQ$YQ", "$argon2id$v=19$m=1024,t=0,p=2$YQ$YQ"} {
+		assert.False(t, PasswordVerify("rasmuslerdorf", h))
+	}
+}
+
+func TestPasswordVerifyLimits(t *testing.T) {
+	hash, err := PasswordHash("hello", PasswordArgon2id, &PasswordOptions{MemoryCost: 1024, TimeCost: 1})
+	assert.Nil(t, err)
+	parts := strings.Split(hash, "$")
+
+	// 超出限制的参数在派生之前就会被拒绝
+	for _, h := range []string{
+		"$argon2id$v=19$m=4294967295,t=1,p=1$" + parts[4] + "$" + parts[5],
+		"$argon2id$v=19$m=1024,t=4294967295,p=1$" + parts[4] + "$" + parts[5],
+		"$argon2id$v=19$m=1024,t=1,p=1$" + parts[4] + "$" + strings.Repeat("A", 1<<20),
+	} {
+		start := time.Now()
+		assert.False(t, PasswordVerify("hello", h))
+		assert.Less(t, time.Since(start), time.Second)
+	}
+
+	_, err = PasswordHash("hello", PasswordArgon2id, &PasswordOptions{MemoryCost: MaxArgon2Memory + 1})
+	assert.NotNil(t, err)
+	_, err = PasswordHash("hello", PasswordArgon2i, &PasswordOptions{MemoryCost: 1024, TimeCost: MaxArgon2Time + 1})
+	assert.NotNil(t, err)
+
+	// 调低限制后拒绝原来的哈希
+	old := MaxArgon2Memory
+	MaxArgon2Memory = 512
+	defer func() { MaxArgon2Memory = old }()
+	assert.False(t, PasswordVerify("hello", hash))
+}
+
+func TestPasswordHash(t *testing.T) {
+	opts := &PasswordOptions{Cost: 4, MemoryCost: 1024, TimeCost: 1, Threads: 2}
+	for _, algo := range []PasswordAlgo{PasswordDefault, PasswordArgon2i, PasswordArgon2id} {
+		hash, err := PasswordHash("hello", algo, opts)
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$"+string(algo)+"$"), hash)
+		assert.True(t, PasswordVerify("hello", hash))
+		assert.False(t, PasswordVerify("hello!", hash))
+		assert.False(t, PasswordNeedsRehash(hash, algo, opts))
+	}
+
+	hash, _ := PasswordHash("hello", PasswordArgon2id, &PasswordOptions{MemoryCost: 1024, TimeCost: 1})
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+
+	_, err := PasswordHash("hello", PasswordBcrypt, &PasswordOptions{Cost: 3})
+	assert.NotNil(t, err)
+	_, err = PasswordHash("hello", "md5", nil)
+	assert.NotNil(t, err)
+
+	// 与php一致，bcrypt 只使用前72个字节
+	long := strings.Repeat("a", 72)
+	hash, err = PasswordHash(long+"b", PasswordBcrypt, opts)
+	assert.Nil(t, err)
+	assert.True(t, PasswordVerify(long+"c", hash))
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	bcryptHash := "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a"
+	assert.False(t, PasswordNeedsRehash(bcryptHash, PasswordDefault, nil))
+	assert.True(t, PasswordNeedsRehash(bcryptHash, PasswordBcrypt, &PasswordOptions{Cost: 12}))
+	assert.True(t, PasswordNeedsRehash(bcryptHash, PasswordArgon2id, nil))
+
+	argonHash := "$argon2i$v=19$m=1024,t=2,p=2$YzJBSzV4TUhkMzc3d3laeg$zqU/1IN0/AogfP4cmSJI1vc8lpXRW9/S0sYY2i2jHT0"
+	assert.False(t, PasswordNeedsRehash(argonHash, PasswordArgon2i, &PasswordOptions{MemoryCost: 1024, TimeCost: 2, Threads: 2}))
+	assert.True(t, PasswordNeedsRehash(argonHash, PasswordArgon2i, nil))
+	assert.True(t, PasswordNeedsRehash(argonHash, PasswordArgon2id, nil))
+	assert.True(t, PasswordNeedsRehash("invalid", PasswordDefault, nil))
+}
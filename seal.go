@@ -0,0 +1,124 @@
+package xutils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+const (
+	// SealKeySize 公钥和私钥的长度，均为 RFC 7748 定义的32字节 X25519 密钥
+	SealKeySize = curve25519.ScalarSize
+
+	sealVersion1 = 1
+	// sealSuiteX25519AESGCM X25519 + HKDF-SHA256 + AES-256-GCM
+	sealSuiteX25519AESGCM = 1
+	sealHeaderSize        = 2 + SealKeySize
+)
+
+// GenerateSealKey 生成 SealTo/Open 使用的 X25519 密钥对
+func GenerateSealKey() (privKey []byte, pubKey []byte, err error) {
+	privKey = make([]byte, SealKeySize)
+	if _, err = io.ReadFull(rand.Reader, privKey); err != nil {
+		return nil, nil, err
+	}
+	pubKey, err = SealPublicKey(privKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privKey, pubKey, nil
+}
+
+// SealPublicKey 根据私钥计算公钥
+func SealPublicKey(privKey []byte) ([]byte, error) {
+	if len(privKey) != SealKeySize {
+		return nil, errors.New("invalid private key size")
+	}
+	return curve25519.X25519(privKey, curve25519.Basepoint)
+}
+
+// EncodeSealKey 将密钥编码为 URL 安全、无填充的 base64 字符串，方便保存到配置中
+func EncodeSealKey(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// DecodeSealKey 解码 EncodeSealKey 编码的密钥，同时兼容标准 base64 编码
+func DecodeSealKey(s string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		if key, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, err
+		}
+	}
+	if len(key) != SealKeySize {
+		return nil, errors.New("invalid key size")
+	}
+	return key, nil
+}
+
+// SealTo 使用接收方的公钥加密数据，只有持有对应私钥的一方才能解密
+// 每次加密生成一个临时 X25519 密钥对，与接收方公钥进行ECDH得到共享密钥，
+// 使用 HKDF-SHA256(共享密钥, salt=临时公钥+接收方公钥, info="xutils sealed box v1") 派生32字节密钥，
+// 再使用 AES-256-GCM 加密，头部作为关联数据
+// 结果: 1字节版本号(1) + 1字节算法(1) + 32字节临时公钥 + 12字节nonce + 密文 + 16字节认证标签
+func SealTo(pubKey []byte, plaintext []byte) ([]byte, error) {
+	if len(pubKey) != SealKeySize {
+		return nil, errors.New("invalid public key size")
+	}
+	ephPriv, ephPub, err := GenerateSealKey()
+	if err != nil {
+		return nil, err
+	}
+	a, err := newSealAEAD(ephPriv, pubKey, ephPub, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, sealHeaderSize)
+	header = append(header, sealVersion1, sealSuiteX25519AESGCM)
+	header = append(header, ephPub...)
+	sealed, err := a.Seal(plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, sealed...), nil
+}
+
+// Open 使用私钥解密 SealTo 加密的数据
+func Open(privKey []byte, sealed []byte) ([]byte, error) {
+	if len(sealed) < sealHeaderSize {
+		return nil, ErrInvalidCiphertext
+	}
+	if sealed[0] != sealVersion1 || sealed[1] != sealSuiteX25519AESGCM {
+		return nil, ErrInvalidCiphertext
+	}
+	pubKey, err := SealPublicKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	header := sealed[:sealHeaderSize]
+	ephPub := header[2:]
+	a, err := newSealAEAD(privKey, ephPub, ephPub, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return a.Open(sealed[sealHeaderSize:], header)
+}
+
+func newSealAEAD(priv, peer, ephPub, recipientPub []byte) (*AEAD, error) {
+	shared, err := curve25519.X25519(priv, peer)
+	if err != nil {
+		// 对方公钥为低阶点
+		return nil, ErrInvalidCiphertext
+	}
+	salt := make([]byte, 0, SealKeySize*2)
+	salt = append(append(salt, ephPub...), recipientPub...)
+	key := make([]byte, 32)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte("xutils sealed box v1")), key); err != nil {
+		return nil, err
+	}
+	return NewAEAD(key)
+}
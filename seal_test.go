@@ -0,0 +1,63 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSealTo(t *testing.T) {
+	priv, pub, err := GenerateSealKey()
+	assert.Nil(t, err)
+	assert.Equal(t, SealKeySize, len(pub))
+
+	text := []byte("hello world")
+	sealed, err := SealTo(pub, text)
+	assert.Nil(t, err)
+	assert.Equal(t, 2+32+12+len(text)+16, len(sealed))
+
+	sealed2, _ := SealTo(pub, text)
+	assert.NotEqual(t, sealed, sealed2)
+
+	text2, err := Open(priv, sealed)
+	assert.Nil(t, err)
+	assert.Equal(t, text, text2)
+
+	// 其他私钥无法解密
+	priv2, _, _ := GenerateSealKey()
+	_, err = Open(priv2, sealed)
+	assert.ErrorIs(t, err, ErrAuthFailed)
+
+	// 篡改临时公钥
+	sealed[5] ^= 1
+	_, err = Open(priv, sealed)
+	assert.NotNil(t, err)
+
+	_, err = Open(priv, sealed[:10])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = SealTo(pub[:10], text)
+	assert.NotNil(t, err)
+
+	// 低阶点公钥
+	_, err = SealTo(make([]byte, 32), text)
+	assert.NotNil(t, err)
+}
+
+func TestSealKeyEncoding(t *testing.T) {
+	priv, pub, _ := GenerateSealKey()
+	s := EncodeSealKey(pub)
+	pub2, err := DecodeSealKey(s)
+	assert.Nil(t, err)
+	assert.Equal(t, pub, pub2)
+
+	pub3, err := SealPublicKey(priv)
+	assert.Nil(t, err)
+	assert.Equal(t, pub, pub3)
+
+	_, err = DecodeSealKey("abc")
+	assert.NotNil(t, err)
+
+	// RFC 7748 6.1 测试向量
+	alicePriv, _ := DecodeSealKey("dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=")
+	alicePub, _ := SealPublicKey(alicePriv)
+	assert.Equal(t, "hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo", EncodeSealKey(alicePub))
+}
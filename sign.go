@@ -0,0 +1,171 @@
+package xutils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+type SignAlgo uint
+
+const (
+	ED25519 SignAlgo = 1 + iota
+	ECDSA_P256
+	RSA_PSS
+)
+
+// RSAKeyBits GenerateKeyPair 生成的RSA密钥长度
+const RSAKeyBits = 2048
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+)
+
+// GenerateKeyPair 生成签名使用的公私钥对
+// ED25519 返回 ed25519.PrivateKey/ed25519.PublicKey，
+// ECDSA_P256 返回 *ecdsa.PrivateKey/*ecdsa.PublicKey，
+// RSA_PSS 返回 *rsa.PrivateKey/*rsa.PublicKey
+func GenerateKeyPair(algo SignAlgo) (crypto.PrivateKey, crypto.PublicKey, error) {
+	switch algo {
+	case ED25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, pub, nil
+	case ECDSA_P256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &priv.PublicKey, nil
+	case RSA_PSS:
+		priv, err := rsa.GenerateKey(rand.Reader, RSAKeyBits)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &priv.PublicKey, nil
+	}
+	return nil, nil, errors.New("unknown sign algorithm")
+}
+
+// Sign 使用私钥对数据签名，签名算法由私钥类型决定
+// Ed25519 直接对数据签名；ECDSA 根据曲线使用 SHA-256/384/512 摘要，签名为ASN.1编码；
+// RSA 使用 RSA-PSS + SHA-256，盐长度等于摘要长度
+func Sign(priv crypto.PrivateKey, data []byte) ([]byte, error) {
+	switch key := priv.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, data), nil
+	case *ecdsa.PrivateKey:
+		digest, err := Hash(ecdsaHashAlgo(key.Curve), data, true)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.SignASN1(rand.Reader, key, digest)
+	case *rsa.PrivateKey:
+		digest, _ := Hash(SHA256, data, true)
+		return rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// Verify 使用公钥校验 Sign 生成的签名，校验失败返回 ErrInvalidSignature
+func Verify(pub crypto.PublicKey, data []byte, sig []byte) error {
+	valid := false
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		valid = len(key) == ed25519.PublicKeySize && ed25519.Verify(key, data, sig)
+	case *ecdsa.PublicKey:
+		digest, err := Hash(ecdsaHashAlgo(key.Curve), data, true)
+		if err != nil {
+			return err
+		}
+		valid = ecdsa.VerifyASN1(key, digest, sig)
+	case *rsa.PublicKey:
+		digest, _ := Hash(SHA256, data, true)
+		valid = rsa.VerifyPSS(key, crypto.SHA256, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+	default:
+		return ErrUnsupportedKey
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// MarshalPrivateKeyPEM 将私钥编码为PKCS#8格式的PEM
+func MarshalPrivateKeyPEM(priv crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM 解析PEM格式的私钥
+// 支持 PKCS#8（PRIVATE KEY）、PKCS#1（RSA PRIVATE KEY）和 SEC 1（EC PRIVATE KEY）格式
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem data found")
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	return nil, errors.New("unsupported pem type: " + block.Type)
+}
+
+// MarshalPublicKeyPEM 将公钥编码为PKIX格式的PEM
+func MarshalPublicKeyPEM(pub crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicKeyPEM 解析PEM格式的公钥
+// 支持 PKIX（PUBLIC KEY）和 PKCS#1（RSA PUBLIC KEY）格式，也可以传入证书（CERTIFICATE）
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem data found")
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	return nil, errors.New("unsupported pem type: " + block.Type)
+}
+
+// ecdsaHashAlgo 根据曲线选择摘要算法
+func ecdsaHashAlgo(curve elliptic.Curve) HashAlgo {
+	bits := curve.Params().BitSize
+	switch {
+	case bits <= 256:
+		return SHA256
+	case bits <= 384:
+		return SHA384
+	}
+	return SHA512
+}
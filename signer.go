@@ -0,0 +1,138 @@
+package xutils
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBadSignature     = errors.New("bad signature")
+	ErrSignatureExpired = errors.New("signature expired")
+)
+
+// Signer 对数据进行带时间戳的HMAC签名，生成URL安全的token，可以用于邮箱验证链接、下载链接等
+// token格式: base64url(payload) + "." + base64url(时间戳) + "." + base64url(签名)
+// payload 只做了编码并未加密，不要放入敏感数据
+type Signer struct {
+	secrets [][]byte
+	algo    HashAlgo
+	salt    string
+	now     func() time.Time
+}
+
+// NewSigner 创建 Signer，secret 为签名使用的密钥，algo 为HMAC使用的哈希算法，必须是密码学哈希算法
+// fallbacks 为轮换前使用的旧密钥，只用于校验，签名总是使用 secret
+func NewSigner(secret []byte, algo HashAlgo, fallbacks ...[]byte) *Signer {
+	return &Signer{
+		secrets: append([][]byte{secret}, fallbacks...),
+		algo:    algo,
+		salt:    "xutils.Signer",
+		now:     time.Now,
+	}
+}
+
+// WithSalt 返回使用指定盐的 Signer，不同用途（如邮箱验证、重置密码）应使用不同的盐，
+// 使一种用途生成的token不能用于另一种用途
+func (s *Signer) WithSalt(salt string) *Signer {
+	s2 := *s
+	s2.salt = salt
+	return &s2
+}
+
+// Sign 对 payload 签名，返回token
+func (s *Signer) Sign(payload []byte) (string, error) {
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(s.now().Unix()))
+	// 去掉时间戳前面的0字节，缩短token长度
+	for len(ts) > 1 && ts[0] == 0 {
+		ts = ts[1:]
+	}
+	value := base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(ts)
+	sig, err := s.signature(s.secrets[0], value)
+	if err != nil {
+		return "", err
+	}
+	return value + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Unsign 校验token并返回 payload
+// maxAge 为token有效期，为0时不检查是否过期
+// 签名错误返回 ErrBadSignature，过期返回 ErrSignatureExpired
+func (s *Signer) Unsign(token string, maxAge time.Duration) ([]byte, error) {
+	payload, ts, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge > 0 && s.now().Sub(ts) > maxAge {
+		return nil, ErrSignatureExpired
+	}
+	return payload, nil
+}
+
+// Timestamp 校验token并返回签名时间
+func (s *Signer) Timestamp(token string) (time.Time, error) {
+	_, ts, err := s.verify(token)
+	return ts, err
+}
+
+func (s *Signer) verify(token string) ([]byte, time.Time, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return nil, time.Time{}, ErrBadSignature
+	}
+	value := token[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return nil, time.Time{}, ErrBadSignature
+	}
+
+	valid := false
+	for _, secret := range s.secrets {
+		expected, err := s.signature(secret, value)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if hmac.Equal(sig, expected) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, time.Time{}, ErrBadSignature
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return nil, time.Time{}, ErrBadSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, time.Time{}, ErrBadSignature
+	}
+	tsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(tsBytes) == 0 || len(tsBytes) > 8 {
+		return nil, time.Time{}, ErrBadSignature
+	}
+	var ts uint64
+	for _, b := range tsBytes {
+		ts = ts<<8 | uint64(b)
+	}
+	return payload, time.Unix(int64(ts), 0), nil
+}
+
+// signature 计算签名，签名密钥由 secret 和 salt 派生
+func (s *Signer) signature(secret []byte, value string) ([]byte, error) {
+	f, err := hmacHash(s.algo)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(f, secret)
+	h.Write([]byte(s.salt))
+	h = hmac.New(f, h.Sum(nil))
+	h.Write([]byte(value))
+	return h.Sum(nil), nil
+}
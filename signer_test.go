@@ -0,0 +1,83 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	s := NewSigner([]byte("secret"), SHA256)
+	token, err := s.Sign([]byte("user@example.com"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(strings.Split(token, ".")))
+
+	payload, err := s.Unsign(token, time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, "user@example.com", string(payload))
+
+	ts, err := s.Timestamp(token)
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now(), ts, 2*time.Second)
+
+	// 篡改数据
+	_, err = s.Unsign("dXNlcjJAZXhhbXBsZS5jb20"+token[strings.IndexByte(token, '.'):], 0)
+	assert.Equal(t, ErrBadSignature, err)
+	_, err = s.Unsign(token[:len(token)-2], 0)
+	assert.Equal(t, ErrBadSignature, err)
+	for _, tk := range []string{"", "abc", "a.b", "a.b.c.d"} {
+		_, err = s.Unsign(tk, 0)
+		assert.Equal(t, ErrBadSignature, err)
+	}
+
+	// 不同的密钥或盐
+	_, err = NewSigner([]byte("other"), SHA256).Unsign(token, 0)
+	assert.Equal(t, ErrBadSignature, err)
+	_, err = s.WithSalt("reset-password").Unsign(token, 0)
+	assert.Equal(t, ErrBadSignature, err)
+
+	_, err = NewSigner([]byte("secret"), HashAlgo(99)).Sign([]byte("a"))
+	assert.NotNil(t, err)
+
+	// 非密码学算法不能用于签名
+	for _, algo := range []HashAlgo{CRC32_IEEE, ADLER32, FNV1A_64, XXH3_64} {
+		_, err = NewSigner([]byte("secret"), algo).Sign([]byte("a"))
+		assert.NotNil(t, err, algo.String())
+	}
+	_, err = NewSigner([]byte("secret"), CRC32_IEEE).Unsign(token, 0)
+	assert.NotNil(t, err)
+}
+
+func TestSignerExpired(t *testing.T) {
+	s := NewSigner([]byte("secret"), SHA1)
+	now := time.Now()
+	s.now = func() time.Time { return now.Add(-2 * time.Hour) }
+	token, _ := s.Sign([]byte("hello"))
+	s.now = func() time.Time { return now }
+
+	_, err := s.Unsign(token, time.Hour)
+	assert.Equal(t, ErrSignatureExpired, err)
+	payload, err := s.Unsign(token, 3*time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(payload))
+	_, err = s.Unsign(token, 0)
+	assert.Nil(t, err)
+}
+
+func TestSignerFallbacks(t *testing.T) {
+	old := NewSigner([]byte("old"), SHA256)
+	token, _ := old.Sign([]byte("hello"))
+
+	s := NewSigner([]byte("new"), SHA256, []byte("old"))
+	payload, err := s.Unsign(token, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(payload))
+
+	// 新签名使用当前密钥
+	token, _ = s.Sign([]byte("hello"))
+	_, err = old.Unsign(token, 0)
+	assert.Equal(t, ErrBadSignature, err)
+	_, err = NewSigner([]byte("new"), SHA256).Unsign(token, 0)
+	assert.Nil(t, err)
+}
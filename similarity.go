@@ -0,0 +1,256 @@
+package xutils
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Tokenizer 将文本切分为用于相似度计算的特征
+type Tokenizer func(text string) []string
+
+// DefaultTokenizer 默认的分词方式：英文、数字等按单词切分并转为小写，
+// 中日韩文字没有空格分隔，按相邻的两个字（bigram）切分，标点和空白会被忽略
+func DefaultTokenizer(text string) []string {
+	tokens := make([]string, 0)
+	var (
+		word []rune
+		cjk  []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			if len(word) > 0 {
+				tokens = append(tokens, strings.ToLower(string(word)))
+				word = word[:0]
+			}
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(cjk) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// WordTokenizer 按空白和标点切分单词并转为小写，只适用于以空格分隔单词的语言
+func WordTokenizer(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NGramTokenizer 返回按字符 n-gram 切分的 Tokenizer，忽略标点和空白并转为小写，
+// 不依赖分词，适用于包括中日韩文字在内的任何语言
+func NGramTokenizer(n int) Tokenizer {
+	if n < 1 {
+		n = 1
+	}
+	return func(text string) []string {
+		runes := make([]rune, 0, len(text))
+		for _, r := range strings.ToLower(text) {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				runes = append(runes, r)
+			}
+		}
+		if len(runes) == 0 {
+			return []string{}
+		}
+		if len(runes) <= n {
+			return []string{string(runes)}
+		}
+		tokens := make([]string, 0, len(runes)-n+1)
+		for i := 0; i+n <= len(runes); i++ {
+			tokens = append(tokens, string(runes[i:i+n]))
+		}
+		return tokens
+	}
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+func tokenize(text string, tokenizer []Tokenizer) []string {
+	if len(tokenizer) > 0 && tokenizer[0] != nil {
+		return tokenizer[0](text)
+	}
+	return DefaultTokenizer(text)
+}
+
+// SimHash 计算文本的 64 位 SimHash 指纹，以词频作为权重，tokenizer 默认为 DefaultTokenizer
+// 相似文本的指纹只有少数位不同，长文本通常以海明距离小于等于 3 作为近似重复的阈值，短文本需要适当放宽
+func SimHash(text string, tokenizer ...Tokenizer) uint64 {
+	weights := make(map[string]int)
+	for _, token := range tokenize(text, tokenizer) {
+		weights[token]++
+	}
+	var v [64]int
+	for token, w := range weights {
+		h := XXH3Hash64([]byte(token), 0)
+		for i := 0; i < 64; i++ {
+			if h&(1<<i) != 0 {
+				v[i] += w
+			} else {
+				v[i] -= w
+			}
+		}
+	}
+	var fp uint64
+	for i := 0; i < 64; i++ {
+		if v[i] > 0 {
+			fp |= 1 << i
+		}
+	}
+	return fp
+}
+
+// HammingDistance 返回两个指纹不同的位数
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// MinHashSignature MinHash 签名
+type MinHashSignature []uint64
+
+// MinHash 计算文本特征集合的 MinHash 签名，k 为签名长度（哈希函数个数），tokenizer 默认为 DefaultTokenizer
+// k 越大 Jaccard 相似度的估计越准确，误差约为 1/sqrt(k)，常用 128，k <= 0 时返回空签名
+func MinHash(text string, k int, tokenizer ...Tokenizer) MinHashSignature {
+	if k <= 0 {
+		return MinHashSignature{}
+	}
+	sig := make(MinHashSignature, k)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	seen := make(map[string]bool)
+	for _, token := range tokenize(text, tokenizer) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		// 使用 h_i(x) = mix(h(x) + i*c) 模拟 k 个独立的哈希函数
+		h := XXH3Hash64([]byte(token), 0)
+		for i := range sig {
+			if v := splitmix64(h + uint64(i)*0x9e3779b97f4a7c15); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Jaccard 估算两个签名对应集合的 Jaccard 相似度，签名长度不同时返回 0
+func (s MinHashSignature) Jaccard(other MinHashSignature) float64 {
+	if len(s) != len(other) || len(s) == 0 {
+		return 0
+	}
+	same := 0
+	for i := range s {
+		if s[i] == other[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(s))
+}
+
+// Bands 将签名分为 b 段，返回每段的哈希值，用于 LSH（局部敏感哈希）分桶
+// 两个签名只要有一段的哈希值相同即可作为候选，相似度阈值约为 (1/b)^(1/r)，r 为每段的长度
+func (s MinHashSignature) Bands(b int) []uint64 {
+	if b <= 0 || b > len(s) {
+		return nil
+	}
+	r := len(s) / b
+	keys := make([]uint64, b)
+	buf := make([]byte, 0, r*8)
+	for i := 0; i < b; i++ {
+		buf = buf[:0]
+		for _, v := range s[i*r : (i+1)*r] {
+			buf = appendUint64(buf, v)
+		}
+		keys[i] = XXH3Hash64(buf, uint64(i))
+	}
+	return keys
+}
+
+func splitmix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// MinHashLSH 基于 MinHash 签名分段的近似重复索引，可以安全地并发使用
+type MinHashLSH struct {
+	mu      sync.RWMutex
+	bands   int
+	buckets []map[uint64][]string
+}
+
+// NewMinHashLSH 创建 LSH 索引，bands 为签名的分段数
+func NewMinHashLSH(bands int) *MinHashLSH {
+	if bands < 1 {
+		bands = 1
+	}
+	l := &MinHashLSH{bands: bands, buckets: make([]map[uint64][]string, bands)}
+	for i := range l.buckets {
+		l.buckets[i] = make(map[uint64][]string)
+	}
+	return l
+}
+
+// Add 将 id 及其签名加入索引，签名长度小于分段数时返回错误
+func (l *MinHashLSH) Add(id string, sig MinHashSignature) error {
+	keys := sig.Bands(l.bands)
+	if keys == nil {
+		return errors.New("minhash signature is shorter than lsh bands")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, key := range keys {
+		l.buckets[i][key] = append(l.buckets[i][key], id)
+	}
+	return nil
+}
+
+// Query 返回与签名至少有一段相同的所有 id（候选的近似重复项），按 id 排序
+// 候选项可以再用 Jaccard 做精确的过滤
+func (l *MinHashLSH) Query(sig MinHashSignature) []string {
+	keys := sig.Bands(l.bands)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	for i, key := range keys {
+		for _, id := range l.buckets[i][key] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
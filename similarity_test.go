@@ -0,0 +1,94 @@
+package xutils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	simText1 = "The quick brown fox jumps over the lazy dog. It was a sunny day in the park and everyone was happy to see the fox running around the trees near the lake."
+	simText2 = "The quick brown fox jumped over the lazy dog! It was a sunny day in the park and everyone was happy to see the fox running around the trees near the lake."
+	simText3 = "Go is an open source programming language that makes it simple to build secure, scalable systems and is used by many companies."
+	cjkText1 = "今天天气很好，我们一起去公园散步，看到了很多美丽的花朵和绿色的树木，大家都非常开心。中午我们在湖边的草地上野餐，孩子们在一旁放风筝，老人们在树荫下下棋聊天。傍晚时分，夕阳把湖面染成了金色，我们才依依不舍地回家。"
+	cjkText2 = "今天天气很好，我们一起去公园散步，看到了许多美丽的花朵和绿色的树木，大家都非常开心。中午我们在湖边的草地上野餐，孩子们在一旁放风筝，老人们在树荫下下棋聊天。傍晚时分，夕阳把湖面染成了金色，我们才依依不舍地回家了。"
+	cjkText3 = "Go语言是一门开源的编程语言，能够让构建简单、可靠且高效的软件变得容易。"
+)
+
+func TestTokenizer(t *testing.T) {
+	assert.Equal(t, []string{"hello", "world", "42"}, DefaultTokenizer("Hello, World! 42"))
+	assert.Equal(t, []string{"今天", "天天", "天气", "go", "语言", "好"}, DefaultTokenizer("今天天气 Go语言，好"))
+	assert.Equal(t, []string{"hello", "world"}, WordTokenizer("  Hello,world "))
+	assert.Equal(t, []string{"ab", "bc", "c中", "中文"}, NGramTokenizer(2)("a b,C中文"))
+	assert.Equal(t, []string{"ab"}, NGramTokenizer(3)("a-b"))
+	assert.Equal(t, []string{}, NGramTokenizer(3)("..."))
+	assert.Equal(t, []string{}, DefaultTokenizer(""))
+}
+
+func TestSimHash(t *testing.T) {
+	h1, h2, h3 := SimHash(simText1), SimHash(simText2), SimHash(simText3)
+	assert.Equal(t, h1, SimHash(strings.ToUpper(simText1)))
+	assert.Equal(t, h1, SimHash(StripTags("<article><p>"+simText1+"</p></article>")))
+	assert.LessOrEqual(t, HammingDistance(h1, h2), 3)
+	assert.Greater(t, HammingDistance(h1, h3), 20)
+
+	c1, c2, c3 := SimHash(cjkText1), SimHash(cjkText2), SimHash(cjkText3)
+	assert.LessOrEqual(t, HammingDistance(c1, c2), 10)
+	assert.Greater(t, HammingDistance(c1, c3), 20)
+
+	// 自定义 tokenizer
+	n1 := SimHash(cjkText1, NGramTokenizer(3))
+	n2 := SimHash(cjkText2, NGramTokenizer(3))
+	assert.NotEqual(t, c1, n1)
+	assert.Less(t, HammingDistance(n1, n2), HammingDistance(n1, c3))
+
+	assert.Equal(t, 0, HammingDistance(0xff, 0xff))
+	assert.Equal(t, 64, HammingDistance(0, ^uint64(0)))
+	assert.Equal(t, 2, HammingDistance(0b1010, 0b0110))
+}
+
+func TestMinHash(t *testing.T) {
+	s1, s2, s3 := MinHash(simText1, 128), MinHash(simText2, 128), MinHash(simText3, 128)
+	assert.Equal(t, 128, len(s1))
+	assert.Equal(t, 1.0, s1.Jaccard(MinHash(simText1, 128)))
+	assert.Greater(t, s1.Jaccard(s2), 0.6)
+	assert.Less(t, s1.Jaccard(s3), 0.2)
+	assert.Equal(t, 0.0, s1.Jaccard(MinHash(simText1, 64)))
+
+	// 与精确的 Jaccard 相似度接近
+	a := strings.Fields("a b c d e f g h i j k l m n o p")
+	b := strings.Fields("a b c d e f g h i j k l q r s t")
+	exact := 12.0 / 20
+	sa := MinHash(strings.Join(a, " "), 256, WordTokenizer)
+	sb := MinHash(strings.Join(b, " "), 256, WordTokenizer)
+	assert.InDelta(t, exact, sa.Jaccard(sb), 0.1)
+
+	c1, c2, c3 := MinHash(cjkText1, 128), MinHash(cjkText2, 128), MinHash(cjkText3, 128)
+	assert.Greater(t, c1.Jaccard(c2), 0.5)
+	assert.Less(t, c1.Jaccard(c3), 0.2)
+
+	assert.Equal(t, MinHashSignature{}, MinHash(simText1, 0))
+	assert.Equal(t, MinHashSignature{}, MinHash(simText1, -1))
+}
+
+func TestMinHashLSH(t *testing.T) {
+	s1, s2, s3 := MinHash(simText1, 128), MinHash(simText2, 128), MinHash(simText3, 128)
+	assert.Equal(t, 32, len(s1.Bands(32)))
+	assert.Equal(t, s1.Bands(32), MinHash(simText1, 128).Bands(32))
+	assert.Nil(t, s1.Bands(0))
+	assert.Nil(t, s1.Bands(129))
+
+	lsh := NewMinHashLSH(32)
+	assert.Nil(t, lsh.Add("doc1", s1))
+	assert.Nil(t, lsh.Add("doc3", s3))
+	assert.Nil(t, lsh.Add("cjk1", MinHash(cjkText1, 128)))
+	assert.Equal(t, []string{"doc1"}, lsh.Query(s2))
+	assert.Equal(t, []string{"doc3"}, lsh.Query(s3))
+	assert.Equal(t, []string{"cjk1"}, lsh.Query(MinHash(cjkText2, 128)))
+	assert.Equal(t, []string{}, lsh.Query(MinHash("something completely different", 128)))
+
+	// 签名长度小于分段数
+	assert.NotNil(t, lsh.Add("short", MinHash(simText1, 16)))
+	assert.NotNil(t, lsh.Add("empty", MinHash(simText1, -1)))
+	assert.Equal(t, []string{}, lsh.Query(MinHash(simText1, 16)))
+}